@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"io/ioutil"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// huntingJobFile is a hunting job, as declared in the hunting jobs file.
+type huntingJobFile struct {
+	Name     string        `yaml:"name"`
+	Interval time.Duration `yaml:"interval"`
+
+	// Query is the query to run, unless SavedQuery is set.
+	Query string `yaml:"query"`
+	// SavedQuery is the name of the saved query to render with Parameters.
+	SavedQuery string            `yaml:"savedQuery"`
+	Parameters map[string]string `yaml:"parameters"`
+}
+
+// readHuntingJobs returns the hunting jobs declared in the provided
+// YAML file, with saved queries looked up in queriesDir.
+func readHuntingJobs(path, queriesDir string) ([]mdatp.HuntingJob, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []huntingJobFile
+	if err := yaml.UnmarshalStrict(b, &entries); err != nil {
+		return nil, fmt.Errorf("could not parse hunting jobs file: %v", err)
+	}
+
+	jobs := make([]mdatp.HuntingJob, 0, len(entries))
+	for _, e := range entries {
+		job := mdatp.HuntingJob{
+			Name:     e.Name,
+			Query:    e.Query,
+			Interval: e.Interval,
+		}
+		switch {
+		case e.SavedQuery != "" && e.Query != "":
+			return nil, fmt.Errorf("hunting job %s: query and savedQuery are mutually exclusive", e.Name)
+		case e.SavedQuery != "":
+			query, err := mdatp.FindSavedQuery(queriesDir, e.SavedQuery)
+			if err != nil {
+				return nil, fmt.Errorf("hunting job %s: %v", e.Name, err)
+			}
+			if job.Query, err = query.Render(e.Parameters); err != nil {
+				return nil, fmt.Errorf("hunting job %s: %v", e.Name, err)
+			}
+		case len(e.Parameters) > 0:
+			return nil, fmt.Errorf("hunting job %s: parameters are only supported by saved queries", e.Name)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
@@ -38,6 +38,10 @@ type configAlertWatch struct {
 	QueryTickerInterval int
 	// QueryMaxInterval is the duration, in minutes, used to set the maxmimum allowed alertCreationTime interval.
 	QueryMaxInterval int
+
+	// HuntingJobsFile is the YAML file declaring the hunting jobs to run.
+	HuntingJobsFile string
+	QueriesDir      string `envconfig:"QUERIES_DIR" default:"./queries"`
 }
 
 func setupCmdAlertWatch(cmd *cobra.Command, c *configAlertWatch) *cobra.Command {
@@ -54,6 +58,9 @@ func setupCmdAlertWatch(cmd *cobra.Command, c *configAlertWatch) *cobra.Command
 	cmd.Flags().IntVarP(&c.QueryTickerInterval, "ticker-interval", "t", c.QueryTickerInterval, "Sets the ticker interval, in seconds, at which to trigger a query to the API. Default is 3 seconds.")
 	cmd.Flags().IntVarP(&c.QueryMaxInterval, "max-interval", "m", c.QueryMaxInterval, "Sets the maxmimum allowed alertCreationTime interval to use before splitting query.")
 
+	cmd.Flags().StringVar(&c.HuntingJobsFile, "hunting-jobs", c.HuntingJobsFile, "Set the YAML file declaring Advanced Hunting queries to run at regular intervals.")
+	cmd.Flags().StringVar(&c.QueriesDir, "queries-dir", c.QueriesDir, "Directory holding saved queries, as .kql files, referenced by hunting jobs.")
+
 	cmd.Flags().BoolVarP(&c.Debug, "debug", "d", c.Debug, "Set log level to DEBUG.")
 	cmd.Flags().BoolVar(&c.JSONLogging, "json", c.JSONLogging, "Set log formatter to JSON.")
 	return cmd
@@ -64,12 +71,27 @@ func newCommandWatch() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "watch",
 		Short: "Query audit records at regular intervals.",
-		Args:  cobra.NoArgs,
+		Long: `Query audit records at regular intervals.
+
+Advanced Hunting queries can also be run at regular intervals, with
+their rows written to the same output. Jobs are declared in a YAML file:
+
+  - name: encoded-powershell
+    interval: 15m
+    query: |
+      DeviceProcessEvents
+      | where Timestamp > WindowStart and Timestamp <= WindowEnd
+      | where ProcessCommandLine has "-enc"
+  - name: device-logons
+    interval: 1h
+    savedQuery: device-logons
+    parameters:
+      DeviceName: host01
+
+Each query can use the WindowStart and WindowEnd datetime values,
+which cover the time since the last successful run of the job.`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			config, err := initConfig(cmdCfg.ConfigFile)
-			if err != nil {
-				return err
-			}
 			logger, err := initLogger(cmd, cmdCfg.LogFile, cmdCfg.Debug, cmdCfg.JSONLogging)
 			if err != nil {
 				return err
@@ -87,6 +109,14 @@ func newCommandWatch() *cobra.Command {
 				}
 			}()
 
+			var huntingJobs []mdatp.HuntingJob
+			if cmdCfg.HuntingJobsFile != "" {
+				huntingJobs, err = readHuntingJobs(cmdCfg.HuntingJobsFile, cmdCfg.QueriesDir)
+				if err != nil {
+					return err
+				}
+			}
+
 			rwc, err := setupOutput(ctx, cmdCfg.Output)
 			if err != nil {
 				return err
@@ -108,14 +138,7 @@ func newCommandWatch() *cobra.Command {
 				}
 			}
 
-			client, err := mdatp.NewClient(
-				mdatp.WithLogger(logger),
-				mdatp.WithOAuthClient(
-					config.Credentials.ClientID,
-					config.Credentials.ClientSecret,
-					config.Credentials.TenantID,
-				),
-			)
+			client, err := newClient(cmdCfg.ConfigFile, mdatp.WithLogger(logger))
 			if err != nil {
 				return err
 			}
@@ -128,6 +151,7 @@ func newCommandWatch() *cobra.Command {
 				HasStateSource:   hasStateSource,
 				QueryInterval:    cmdCfg.QueryTickerInterval,
 				QueryMaxInterval: cmdCfg.QueryMaxInterval,
+				HuntingJobs:      huntingJobs,
 			}
 			if err := client.Alert.Watch(ctx, req); err != nil {
 				return err
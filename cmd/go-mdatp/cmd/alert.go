@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"go-mdatp/pkg/mdatp"
 
 	"github.com/kelseyhightower/envconfig"
@@ -31,6 +32,10 @@ func newCommandAlert() *cobra.Command {
 	}
 	cmd.AddCommand(
 		newCommandAlertList(),
+		newCommandAlertGet(),
+		newCommandAlertUpdate(),
+		newCommandAlertBatchUpdate(),
+		newCommandAlertEntities(),
 		newCommandWatch(),
 	)
 	return setupCmdAlert(cmd, &config)
@@ -54,45 +59,220 @@ func newCommandAlertList() *cobra.Command {
 		Short: "List alerts.",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			config, err := initConfig(config.ConfigFile)
+			client, err := newClient(config.ConfigFile)
 			if err != nil {
 				return err
 			}
 
-			client, err := mdatp.NewClient(
-				mdatp.WithOAuthClient(
-					config.Credentials.ClientID,
-					config.Credentials.ClientSecret,
-					config.Credentials.TenantID,
-				),
-			)
+			_, err = client.Alert.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.AlertListResponse) error {
+				for _, a := range page.Value {
+					marshalled, err := json.Marshal(a)
+					if err != nil {
+						return err
+					}
+					writeOut(string(marshalled))
+				}
+				return nil
+			})
+			return err
+		},
+	}
+	return setupCmdAlertList(cmd, &cmdConfig)
+}
+
+type configAlertGet struct {
+	IDs []string
+}
+
+func setupCmdAlertGet(cmd *cobra.Command, c *configAlertGet) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringSliceVarP(&c.IDs, "id", "I", c.IDs, "Alert ID. Can be repeated. Default is to read IDs from stdin, one per line.")
+	return cmd
+}
+
+func newCommandAlertGet() *cobra.Command {
+	var cmdConfig configAlertGet
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get alerts by ID.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := readIDs(cmdConfig.IDs)
 			if err != nil {
 				return err
 			}
-
-			resp, alert, err := client.Alert.List(context.Background(), cmdConfig.ODataQueryFilter)
+			client, err := newClient(config.ConfigFile)
 			if err != nil {
 				return err
 			}
 
-			if resp.APIError != nil {
-				marshalled, err := json.Marshal(resp.APIError)
+			for _, id := range ids {
+				_, alert, err := client.Alert.Get(context.Background(), id)
 				if err != nil {
 					return err
 				}
-				writeOut(string(marshalled))
-				return nil
+				if err := writeJSON(alert); err != nil {
+					return err
+				}
 			}
+			return nil
+		},
+	}
+	return setupCmdAlertGet(cmd, &cmdConfig)
+}
+
+type configAlertUpdate struct {
+	IDs []string
+
+	Status         string
+	AssignedTo     string
+	Classification string
+	Determination  string
+	Comment        string
+}
+
+// alertUpdate returns an AlertUpdate holding the attributes
+// that were provided, or an error if none were.
+func (c *configAlertUpdate) alertUpdate() (*mdatp.AlertUpdate, error) {
+	var update mdatp.AlertUpdate
+	var isSet bool
+	for _, attr := range []struct {
+		value string
+		field **string
+	}{
+		{c.Status, &update.Status},
+		{c.AssignedTo, &update.AssignedTo},
+		{c.Classification, &update.Classification},
+		{c.Determination, &update.Determination},
+		{c.Comment, &update.Comment},
+	} {
+		if attr.value != "" {
+			*attr.field = mdatp.String(attr.value)
+			isSet = true
+		}
+	}
+	if !isSet {
+		return nil, errors.New("at least one attribute to update is required")
+	}
+	return &update, nil
+}
 
-			for _, a := range alert.Value {
-				marshalled, err := json.Marshal(a)
+func setupCmdAlertUpdate(cmd *cobra.Command, c *configAlertUpdate) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringSliceVarP(&c.IDs, "id", "I", c.IDs, "Alert ID. Can be repeated. Default is to read IDs from stdin, one per line.")
+	cmd.Flags().StringVar(&c.Status, "status", c.Status, "Set the status. One of: New, InProgress, Resolved.")
+	cmd.Flags().StringVar(&c.AssignedTo, "assigned-to", c.AssignedTo, "Set the owner of the alert.")
+	cmd.Flags().StringVar(&c.Classification, "classification", c.Classification, "Set the classification. One of: Unknown, FalsePositive, TruePositive.")
+	cmd.Flags().StringVar(&c.Determination, "determination", c.Determination, "Set the determination. One of: NotAvailable, Apt, Malware, SecurityPersonnel, SecurityTesting, UnwantedSoftware, Other.")
+	cmd.Flags().StringVar(&c.Comment, "comment", c.Comment, "Add a comment to the alert.")
+	return cmd
+}
+
+func newCommandAlertUpdate() *cobra.Command {
+	var cmdConfig configAlertUpdate
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update alerts one by one and print the updated alerts.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update, err := cmdConfig.alertUpdate()
+			if err != nil {
+				return err
+			}
+			ids, err := readIDs(cmdConfig.IDs)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(config.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				_, alert, err := client.Alert.Update(context.Background(), id, update)
 				if err != nil {
 					return err
 				}
-				writeOut(string(marshalled))
+				if err := writeJSON(alert); err != nil {
+					return err
+				}
 			}
 			return nil
 		},
 	}
-	return setupCmdAlertList(cmd, &cmdConfig)
+	return setupCmdAlertUpdate(cmd, &cmdConfig)
+}
+
+func newCommandAlertBatchUpdate() *cobra.Command {
+	var cmdConfig configAlertUpdate
+	cmd := &cobra.Command{
+		Use:   "batch-update",
+		Short: "Update alerts using a single batch request.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update, err := cmdConfig.alertUpdate()
+			if err != nil {
+				return err
+			}
+			ids, err := readIDs(cmdConfig.IDs)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(config.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Alert.BatchUpdate(context.Background(), ids, update)
+			return err
+		},
+	}
+	return setupCmdAlertUpdate(cmd, &cmdConfig)
+}
+
+// alertEntities is the JSON document printed by the alert entities command.
+type alertEntities struct {
+	AlertID string         `json:"alertId"`
+	Files   []mdatp.File   `json:"files"`
+	IPs     []mdatp.IP     `json:"ips"`
+	Domains []mdatp.Domain `json:"domains"`
+	User    *mdatp.User    `json:"user"`
+	Machine *mdatp.Machine `json:"machine"`
+}
+
+func newCommandAlertEntities() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "entities <id>",
+		Short: "Print the files, IPs, domains, user and machine related to an alert.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(config.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			entities := alertEntities{AlertID: args[0]}
+			if _, entities.Files, err = client.Alert.ListFiles(ctx, args[0]); err != nil {
+				return err
+			}
+			if _, entities.IPs, err = client.Alert.ListIPs(ctx, args[0]); err != nil {
+				return err
+			}
+			if _, entities.Domains, err = client.Alert.ListDomains(ctx, args[0]); err != nil {
+				return err
+			}
+			// not every alert is related to a user or a machine.
+			if _, entities.User, err = client.Alert.GetUser(ctx, args[0]); err != nil && !errors.Is(err, mdatp.ErrNotFound) {
+				return err
+			}
+			if _, entities.Machine, err = client.Alert.GetMachine(ctx, args[0]); err != nil && !errors.Is(err, mdatp.ErrNotFound) {
+				return err
+			}
+			return writeJSON(entities)
+		},
+	}
+	return cmd
 }
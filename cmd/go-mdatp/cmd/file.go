@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"sync"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fileConfig configFile
+)
+
+type configFile struct {
+	ConfigFile string
+}
+
+func setupCmdFile(cmd *cobra.Command, c *configFile) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandFile() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "file",
+		Short: "File resource type commands.",
+	}
+	cmd.AddCommand(
+		newCommandFileQuarantine(),
+	)
+	return setupCmdFile(cmd, &fileConfig)
+}
+
+type configFileQuarantine struct {
+	Comment     string
+	Concurrency int `default:"4"`
+
+	configMachineActionWait
+}
+
+func setupCmdFileQuarantine(cmd *cobra.Command, c *configFileQuarantine) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Comment, "comment", "m", c.Comment, "Comment to associate with the actions. Required.")
+	cmd.MarkFlagRequired("comment")
+	cmd.Flags().IntVarP(&c.Concurrency, "concurrency", "n", c.Concurrency, "Maximum number of machines handled at the same time.")
+	setupFlagsMachineActionWait(cmd, &c.configMachineActionWait)
+	return cmd
+}
+
+// quarantineStatus is the outcome of quarantining a file on a machine,
+// as printed by the file quarantine command.
+type quarantineStatus struct {
+	MachineID       string `json:"machineId"`
+	ComputerDNSName string `json:"computerDnsName"`
+	ActionID        string `json:"actionId,omitempty"`
+	Status          string `json:"status,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// quarantineFile submits a stop and quarantine action on the machine,
+// waiting for it to complete if requested.
+func quarantineFile(ctx context.Context, client *mdatp.Client, machine mdatp.Machine, sha1 string, c *configFileQuarantine) quarantineStatus {
+	status := quarantineStatus{
+		MachineID:       mdatp.StringValue(machine.ID),
+		ComputerDNSName: mdatp.StringValue(machine.ComputerDNSName),
+	}
+	_, machineAction, err := client.MachineAction.StopAndQuarantineFile(ctx, status.MachineID, sha1, c.Comment)
+	var actionID string
+	if machineAction != nil {
+		actionID = mdatp.StringValue(machineAction.ID)
+	}
+	if err == nil && actionID == "" {
+		err = errors.New("machine action returned without ID")
+	}
+	if err == nil && c.Wait {
+		pollInterval := time.Duration(c.PollInterval) * time.Second
+		var completed *mdatp.MachineAction
+		// keep the submitted action on error, so that it can be tracked.
+		if _, completed, err = client.MachineAction.WaitForCompletion(ctx, actionID, pollInterval); err == nil {
+			machineAction = completed
+		}
+	}
+	if machineAction != nil {
+		status.ActionID = mdatp.StringValue(machineAction.ID)
+		status.Status = mdatp.StringValue(machineAction.Status)
+	}
+	if err != nil {
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func newCommandFileQuarantine() *cobra.Command {
+	var cmdConfig configFileQuarantine
+	cmd := &cobra.Command{
+		Use:   "quarantine <sha1>",
+		Short: "Stop and quarantine a file on every machine it was seen on.",
+		Long: `Stop and quarantine a file on every machine it was seen on.
+
+The machines are retrieved from the file, identified by its SHA1 hash,
+and a stop and quarantine action is submitted to each of them, up to
+--concurrency at the same time. The outcome for each machine is printed
+as a JSON line, once all machines are handled.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sha1 := args[0]
+			if !sha1Regexp.MatchString(sha1) {
+				return fmt.Errorf("invalid SHA1 hash: %s", sha1)
+			}
+			if cmdConfig.Concurrency < 1 {
+				return errors.New("concurrency must be at least 1")
+			}
+			if cmdConfig.Wait {
+				if err := cmdConfig.validate(); err != nil {
+					return err
+				}
+			}
+			client, err := newClient(fileConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			_, machines, err := client.File.ListMachines(ctx, sha1)
+			if err != nil {
+				return err
+			}
+
+			statuses := make([]quarantineStatus, len(machines))
+			sem := make(chan struct{}, cmdConfig.Concurrency)
+			var wg sync.WaitGroup
+			for i, machine := range machines {
+				wg.Add(1)
+				sem <- struct{}{}
+				go func(i int, machine mdatp.Machine) {
+					defer func() {
+						<-sem
+						wg.Done()
+					}()
+					statuses[i] = quarantineFile(ctx, client, machine, sha1, &cmdConfig)
+				}(i, machine)
+			}
+			wg.Wait()
+
+			var failed int
+			for _, status := range statuses {
+				if status.Error != "" || (cmdConfig.Wait && status.Status != mdatp.MachineActionStatusSucceeded) {
+					failed++
+				}
+				if err := writeJSON(status); err != nil {
+					return err
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("file could not be quarantined on %d of %d machines", failed, len(machines))
+			}
+			return nil
+		},
+	}
+	return setupCmdFileQuarantine(cmd, &cmdConfig)
+}
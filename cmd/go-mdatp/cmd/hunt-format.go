@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	huntFormatTable = "table"
+	huntFormatCSV   = "csv"
+	huntFormatTSV   = "tsv"
+	huntFormatJSONL = "jsonl"
+	huntFormatJSON  = "json"
+)
+
+var (
+	huntFormats = []string{huntFormatTable, huntFormatCSV, huntFormatTSV, huntFormatJSONL, huntFormatJSON}
+
+	tsvReplacer   = strings.NewReplacer("\\", "\\\\", "\t", "\\t", "\n", "\\n", "\r", "\\r")
+	tableReplacer = strings.NewReplacer("\t", " ", "\n", " ", "\r", " ")
+)
+
+// writeHuntingResult writes result to w using the provided format,
+// with columns in the order of the result schema. In table format,
+// values are truncated to maxWidth characters, unless it is 0.
+func writeHuntingResult(w io.Writer, format string, result *mdatp.HuntingResult, maxWidth int) error {
+	columns := huntingColumns(result)
+	switch format {
+	case huntFormatTable:
+		return writeHuntingTable(w, columns, result.Results, maxWidth)
+	case huntFormatCSV:
+		return writeHuntingCSV(w, columns, result.Results)
+	case huntFormatTSV:
+		return writeHuntingTSV(w, columns, result.Results)
+	case huntFormatJSONL:
+		for _, row := range result.Results {
+			b, err := marshalHuntingRow(columns, row)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", b); err != nil {
+				return err
+			}
+		}
+		return nil
+	case huntFormatJSON:
+		rows := make([]json.RawMessage, 0, len(result.Results))
+		for _, row := range result.Results {
+			b, err := marshalHuntingRow(columns, row)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, b)
+		}
+		b, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	default:
+		return fmt.Errorf("invalid format %q, must be one of: %s", format, strings.Join(huntFormats, ", "))
+	}
+}
+
+// huntingColumns returns the columns of result in the order of its schema,
+// followed by the columns of its rows missing from the schema, sorted.
+func huntingColumns(result *mdatp.HuntingResult) []mdatp.HuntingColumn {
+	columns := make([]mdatp.HuntingColumn, 0, len(result.Schema))
+	known := make(map[string]bool, len(result.Schema))
+	for _, c := range result.Schema {
+		columns = append(columns, c)
+		known[c.Name] = true
+	}
+	var extra []string
+	for _, row := range result.Results {
+		for name := range row {
+			if !known[name] {
+				known[name] = true
+				extra = append(extra, name)
+			}
+		}
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		columns = append(columns, mdatp.HuntingColumn{Name: name})
+	}
+	return columns
+}
+
+// marshalHuntingRow returns the JSON encoding of row,
+// with its keys in the order of columns.
+func marshalHuntingRow(columns []mdatp.HuntingColumn, row mdatp.HuntingRow) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, c := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(c.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(row[c.Name])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// formatHuntingValue returns the text representation of v, a value of
+// column c. Datetimes are normalized to RFC 3339 in UTC, and dynamic
+// values are written as JSON.
+func formatHuntingValue(c mdatp.HuntingColumn, v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		if strings.EqualFold(c.Type, "DateTime") {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t.UTC().Format(time.RFC3339Nano), nil
+			}
+		}
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
+
+// huntingRecords returns the header and the text representation of rows.
+func huntingRecords(columns []mdatp.HuntingColumn, rows []mdatp.HuntingRow, fn func(string) string) ([][]string, error) {
+	records := make([][]string, 0, len(rows)+1)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = fn(c.Name)
+	}
+	records = append(records, header)
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			s, err := formatHuntingValue(c, row[c.Name])
+			if err != nil {
+				return nil, err
+			}
+			record[i] = fn(s)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func writeHuntingCSV(w io.Writer, columns []mdatp.HuntingColumn, rows []mdatp.HuntingRow) error {
+	records, err := huntingRecords(columns, rows, func(s string) string { return s })
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	return cw.WriteAll(records)
+}
+
+func writeHuntingTSV(w io.Writer, columns []mdatp.HuntingColumn, rows []mdatp.HuntingRow) error {
+	records, err := huntingRecords(columns, rows, tsvReplacer.Replace)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if _, err := fmt.Fprintln(w, strings.Join(record, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeHuntingTable(w io.Writer, columns []mdatp.HuntingColumn, rows []mdatp.HuntingRow, maxWidth int) error {
+	records, err := huntingRecords(columns, rows, func(s string) string {
+		return truncate(tableReplacer.Replace(s), maxWidth)
+	})
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, record := range records {
+		if _, err := fmt.Fprintln(tw, strings.Join(record, "\t")); err != nil {
+			return err
+		}
+	}
+	return tw.Flush()
+}
+
+// truncate returns s truncated to width characters, with an ellipsis
+// replacing the last one if truncated. A width of 0 disables truncation.
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+func isHuntFormat(format string) bool {
+	for _, f := range huntFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
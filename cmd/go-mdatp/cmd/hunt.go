@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	huntConfig configHunt
+)
+
+type configHunt struct {
+	ConfigFile string
+	QueriesDir string `envconfig:"QUERIES_DIR" default:"./queries"`
+}
+
+func setupCmdHuntRoot(cmd *cobra.Command, c *configHunt) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	cmd.PersistentFlags().StringVar(&c.QueriesDir, "queries-dir", c.QueriesDir, "Directory holding saved queries, as .kql files.")
+	return cmd
+}
+
+type configHuntRun struct {
+	File   string
+	Name   string
+	Params map[string]string
+
+	Format   string
+	MaxWidth int
+}
+
+func setupCmdHunt(cmd *cobra.Command, c *configHuntRun) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.File, "file", "f", c.File, "File holding the query. Default is to read the query from stdin if not provided as argument.")
+	cmd.Flags().StringVarP(&c.Name, "name", "n", c.Name, "Name of the saved query to run.")
+	cmd.Flags().StringToStringVarP(&c.Params, "param", "p", c.Params, "Parameter of the saved query, as name=value. Can be repeated.")
+	cmd.Flags().StringVarP(&c.Format, "format", "o", huntFormatJSONL, "Output format, one of: "+strings.Join(huntFormats, ", ")+".")
+	cmd.Flags().IntVar(&c.MaxWidth, "max-width", 40, "Maximum width of the columns in table format. 0 disables truncation.")
+	return cmd
+}
+
+// readQuery returns the rendered saved query, if a name is configured,
+// or the query provided as argument, or read from the configured file,
+// or from stdin.
+func (c *configHuntRun) readQuery(args []string) (string, error) {
+	if c.Name != "" {
+		if len(args) > 0 || c.File != "" {
+			return "", errors.New("a saved query cannot be combined with a query argument or file")
+		}
+		query, err := mdatp.FindSavedQuery(huntConfig.QueriesDir, c.Name)
+		if err != nil {
+			return "", err
+		}
+		return query.Render(c.Params)
+	}
+	if len(c.Params) > 0 {
+		return "", errors.New("parameters are only supported by saved queries")
+	}
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	r, err := openInput(c.File)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+	query, err := ioutil.ReadAll(r)
+	return string(query), err
+}
+
+func newCommandHunt() *cobra.Command {
+	var cmdConfig configHuntRun
+	cmd := &cobra.Command{
+		Use:   "hunt [query]",
+		Short: "Run Advanced Hunting queries.",
+		Long: `Run an Advanced Hunting query and print the resulting rows, as JSON lines
+by default, or using --format as a table, CSV, TSV or a single JSON array.
+Columns are ordered as in the query results.
+
+The KQL query is taken from the argument, the file provided using --file, or stdin.
+Saved queries are run using --name, with their parameters provided using --param.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isHuntFormat(cmdConfig.Format) {
+				return fmt.Errorf("invalid format %q, must be one of: %s", cmdConfig.Format, strings.Join(huntFormats, ", "))
+			}
+			query, err := cmdConfig.readQuery(args)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(huntConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, result, err := client.Hunting.Run(context.Background(), query)
+			if err != nil {
+				return err
+			}
+			return writeHuntingResult(defaultOutput, cmdConfig.Format, result, cmdConfig.MaxWidth)
+		},
+	}
+	cmd.AddCommand(
+		newCommandHuntQueries(),
+	)
+	setupCmdHunt(cmd, &cmdConfig)
+	return setupCmdHuntRoot(cmd, &huntConfig)
+}
+
+func newCommandHuntQueries() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queries [name]",
+		Short: "List saved queries, or describe one of them.",
+		Long: `List saved queries, or describe one of them.
+
+Saved queries are .kql files starting with an optional YAML front-matter,
+delimited by --- lines, followed by the query as a Go template:
+
+  ---
+  name: device-logons
+  description: Logons on a device.
+  parameters:
+    - name: DeviceName
+      required: true
+    - name: Lookback
+      default: 7d
+  ---
+  DeviceLogonEvents
+  | where Timestamp > ago({{ .Lookback }})
+  | where DeviceName == {{ quote .DeviceName }}
+
+The quote function returns its argument as a KQL string literal.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				query, err := mdatp.FindSavedQuery(huntConfig.QueriesDir, args[0])
+				if err != nil {
+					return err
+				}
+				return writeJSON(query)
+			}
+
+			queries, err := mdatp.LoadSavedQueries(huntConfig.QueriesDir)
+			if err != nil {
+				return err
+			}
+			for _, q := range queries {
+				q.Query = ""
+				if err := writeJSON(q); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
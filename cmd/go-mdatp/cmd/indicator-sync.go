@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// indicatorReaders maps the formats supported by the sync
+	// command to the function decoding indicators from them.
+	indicatorReaders = map[string]func(io.Reader) ([]mdatp.Indicator, error){
+		"csv":  mdatp.ReadIndicatorsCSV,
+		"json": mdatp.ReadIndicatorsJSON,
+		"stix": mdatp.ReadIndicatorsSTIX,
+	}
+	// indicatorFormatExtensions maps file extensions to formats.
+	indicatorFormatExtensions = map[string]string{
+		".csv":    "csv",
+		".json":   "json",
+		".jsonl":  "stix",
+		".ndjson": "stix",
+		".stix":   "stix",
+	}
+)
+
+type configIndicatorSync struct {
+	File          string
+	Format        string
+	Application   string
+	DefaultAction string
+	DeleteStale   bool
+	DryRun        bool
+}
+
+func setupCmdIndicatorSync(cmd *cobra.Command, c *configIndicatorSync) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.File, "file", "f", c.File, "File holding the desired indicators. Required.")
+	cmd.MarkFlagRequired("file")
+	cmd.Flags().StringVar(&c.Format, "format", c.Format, "Format of the file. One of: csv, json, stix. Default is to guess from the file extension.")
+	cmd.Flags().StringVar(&c.Application, "application", c.Application, "Application owning the indicators. Set on created indicators that do not define one.")
+	cmd.Flags().StringVar(&c.DefaultAction, "default-action", string(mdatp.IndicatorActionAlert), "Action set on indicators that do not define one.")
+	cmd.Flags().BoolVar(&c.DeleteStale, "delete-stale", c.DeleteStale, "Delete indicators owned by the application that are not in the file.")
+	cmd.Flags().BoolVar(&c.DryRun, "dry-run", c.DryRun, "Print the changes without applying them.")
+	return cmd
+}
+
+// readIndicators decodes the indicators of the configured file.
+func (c *configIndicatorSync) readIndicators() ([]mdatp.Indicator, error) {
+	format := c.Format
+	if format == "" {
+		format = indicatorFormatExtensions[strings.ToLower(filepath.Ext(c.File))]
+	}
+	read, ok := indicatorReaders[format]
+	if !ok {
+		return nil, fmt.Errorf("could not determine the format of %s, use --format", c.File)
+	}
+	r, err := openInput(c.File)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	indicators, err := read(r)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode indicators: %v", err)
+	}
+	for i := range indicators {
+		if indicators[i].Action == "" {
+			indicators[i].Action = mdatp.IndicatorAction(c.DefaultAction)
+		}
+	}
+	return indicators, nil
+}
+
+func newCommandIndicatorSync() *cobra.Command {
+	var cmdConfig configIndicatorSync
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Reconcile the indicators of the tenant with the indicators of a file.",
+		Long: `Reconcile the indicators of the tenant with the indicators of a file.
+
+Missing indicators are created, and indicators whose action or expiration
+time changed are updated. The changes are printed, one JSON document per
+line, before being applied.
+
+Supported formats are CSV with a header naming indicator attributes, a JSON
+array of indicators, and STIX 2 indicator objects as JSON lines.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			indicators, err := cmdConfig.readIndicators()
+			if err != nil {
+				return err
+			}
+			client, err := newClient(indicatorConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			opts := &mdatp.IndicatorSyncOptions{
+				Application: cmdConfig.Application,
+				DeleteStale: cmdConfig.DeleteStale,
+			}
+			plan, err := client.Indicator.PlanSync(ctx, indicators, opts)
+			if err != nil {
+				return err
+			}
+			for _, change := range plan.Changes {
+				if err := writeJSON(change); err != nil {
+					return err
+				}
+			}
+			if cmdConfig.DryRun || len(plan.Changes) == 0 {
+				return nil
+			}
+
+			results, err := client.Indicator.ApplySync(ctx, plan)
+			if err != nil {
+				return err
+			}
+			var failed int
+			for _, result := range results {
+				if result.IsFailed != nil && *result.IsFailed {
+					failed++
+					if err := writeJSON(result); err != nil {
+						return err
+					}
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d indicators could not be imported", failed)
+			}
+			return nil
+		},
+	}
+	return setupCmdIndicatorSync(cmd, &cmdConfig)
+}
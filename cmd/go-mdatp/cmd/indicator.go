@@ -0,0 +1,239 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	indicatorConfig configIndicator
+)
+
+type configIndicator struct {
+	ConfigFile string
+}
+
+func setupCmdIndicator(cmd *cobra.Command, c *configIndicator) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandIndicator() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "indicator",
+		Short: "Indicator resource type commands.",
+	}
+	cmd.AddCommand(
+		newCommandIndicatorList(),
+		newCommandIndicatorAdd(),
+		newCommandIndicatorDelete(),
+		newCommandIndicatorImport(),
+		newCommandIndicatorSync(),
+	)
+	return setupCmdIndicator(cmd, &indicatorConfig)
+}
+
+type configIndicatorList struct {
+	ODataQueryFilter string
+}
+
+func setupCmdIndicatorList(cmd *cobra.Command, c *configIndicatorList) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.ODataQueryFilter, "query-filter", "f", c.ODataQueryFilter, "$filter OData V4 query option string.")
+	return cmd
+}
+
+func newCommandIndicatorList() *cobra.Command {
+	var cmdConfig configIndicatorList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List indicators.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(indicatorConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Indicator.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.IndicatorListResponse) error {
+				for _, i := range page.Value {
+					if err := writeJSON(i); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			return err
+		},
+	}
+	return setupCmdIndicatorList(cmd, &cmdConfig)
+}
+
+type configIndicatorAdd struct {
+	Value              string
+	Type               string
+	Action             string
+	Title              string
+	Description        string
+	Severity           string
+	Application        string
+	RecommendedActions string
+	Expiration         string
+	GenerateAlert      bool
+}
+
+func setupCmdIndicatorAdd(cmd *cobra.Command, c *configIndicatorAdd) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVar(&c.Value, "value", c.Value, "Value of the indicator. Required.")
+	cmd.Flags().StringVarP(&c.Type, "type", "t", c.Type, "Type of the indicator. One of: FileSha1, FileSha256, FileMd5, CertificateThumbprint, IpAddress, DomainName, Url. Required.")
+	cmd.Flags().StringVarP(&c.Action, "action", "a", c.Action, "Action taken on match. One of: Alert, AlertAndBlock, Allowed, Block, Audit, Warn. Required.")
+	cmd.Flags().StringVar(&c.Title, "title", c.Title, "Title of the indicator. Required.")
+	cmd.Flags().StringVar(&c.Description, "description", c.Description, "Description of the indicator. Required.")
+	cmd.Flags().StringVar(&c.Severity, "severity", c.Severity, "Severity of the indicator. One of: Informational, Low, Medium, High.")
+	cmd.Flags().StringVar(&c.Application, "application", c.Application, "Application associated with the indicator.")
+	cmd.Flags().StringVar(&c.RecommendedActions, "recommended-actions", c.RecommendedActions, "Recommended actions for the indicator.")
+	cmd.Flags().StringVar(&c.Expiration, "expiration", c.Expiration, "UTC expiration time, as YYYY-MM-DD[THH:MM[:SS]]. Default is to never expire.")
+	cmd.Flags().BoolVar(&c.GenerateAlert, "generate-alert", c.GenerateAlert, "Generate an alert on match.")
+	for _, name := range []string{"value", "type", "action", "title", "description"} {
+		cmd.MarkFlagRequired(name)
+	}
+	return cmd
+}
+
+// indicator returns the indicator described by the flags.
+func (c *configIndicatorAdd) indicator() (*mdatp.Indicator, error) {
+	indicator := &mdatp.Indicator{
+		IndicatorValue: mdatp.String(c.Value),
+		IndicatorType:  mdatp.IndicatorType(c.Type),
+		Action:         mdatp.IndicatorAction(c.Action),
+		Title:          mdatp.String(c.Title),
+		Description:    mdatp.String(c.Description),
+	}
+	for _, attr := range []struct {
+		value string
+		field **string
+	}{
+		{c.Severity, &indicator.Severity},
+		{c.Application, &indicator.Application},
+		{c.RecommendedActions, &indicator.RecommendedActions},
+	} {
+		if attr.value != "" {
+			*attr.field = mdatp.String(attr.value)
+		}
+	}
+	if c.Expiration != "" {
+		expiration, err := parseDate(c.Expiration)
+		if err != nil {
+			return nil, err
+		}
+		indicator.ExpirationTime = mdatp.String(expiration.UTC().Format(time.RFC3339))
+	}
+	if c.GenerateAlert {
+		indicator.GenerateAlert = mdatp.Bool(true)
+	}
+	return indicator, nil
+}
+
+func newCommandIndicatorAdd() *cobra.Command {
+	var cmdConfig configIndicatorAdd
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Submit an indicator, or update the existing one having the same value and type.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			indicator, err := cmdConfig.indicator()
+			if err != nil {
+				return err
+			}
+			client, err := newClient(indicatorConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, indicator, err = client.Indicator.Submit(context.Background(), indicator)
+			if err != nil {
+				return err
+			}
+			return writeJSON(indicator)
+		},
+	}
+	return setupCmdIndicatorAdd(cmd, &cmdConfig)
+}
+
+func newCommandIndicatorDelete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <id>...",
+		Short: "Delete indicators by ID.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(indicatorConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				if _, err := client.Indicator.Delete(context.Background(), id); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+type configIndicatorImport struct {
+	File string
+}
+
+func setupCmdIndicatorImport(cmd *cobra.Command, c *configIndicatorImport) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.File, "file", "f", c.File, "JSON file holding an array of indicators. Default is to read from stdin.")
+	return cmd
+}
+
+func newCommandIndicatorImport() *cobra.Command {
+	var cmdConfig configIndicatorImport
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import indicators in batches and print the result for each of them.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			r, err := openInput(cmdConfig.File)
+			if err != nil {
+				return err
+			}
+			var indicators []mdatp.Indicator
+			err = json.NewDecoder(r).Decode(&indicators)
+			r.Close()
+			if err != nil {
+				return fmt.Errorf("could not decode indicators: %v", err)
+			}
+
+			client, err := newClient(indicatorConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, results, err := client.Indicator.Import(context.Background(), indicators)
+			for _, result := range results {
+				if err := writeJSON(result); err != nil {
+					return err
+				}
+			}
+			return err
+		},
+	}
+	return setupCmdIndicatorImport(cmd, &cmdConfig)
+}
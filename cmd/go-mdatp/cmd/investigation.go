@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"strconv"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	investigationConfig configInvestigation
+)
+
+type configInvestigation struct {
+	ConfigFile string
+}
+
+func setupCmdInvestigation(cmd *cobra.Command, c *configInvestigation) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandInvestigation() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "investigation",
+		Short: "Investigation resource type commands.",
+	}
+	cmd.AddCommand(
+		newCommandInvestigationList(),
+		newCommandInvestigationGet(),
+		newCommandInvestigationStart(),
+	)
+	return setupCmdInvestigation(cmd, &investigationConfig)
+}
+
+type configInvestigationList struct {
+	ODataQueryFilter string
+}
+
+func setupCmdInvestigationList(cmd *cobra.Command, c *configInvestigationList) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.ODataQueryFilter, "query-filter", "f", c.ODataQueryFilter, "$filter OData V4 query option string.")
+	return cmd
+}
+
+func newCommandInvestigationList() *cobra.Command {
+	var cmdConfig configInvestigationList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List automated investigations.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(investigationConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Investigation.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.InvestigationListResponse) error {
+				for _, investigation := range page.Value {
+					if err := writeJSON(investigation); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			return err
+		},
+	}
+	return setupCmdInvestigationList(cmd, &cmdConfig)
+}
+
+type configInvestigationGet struct {
+	IDs      []string
+	AlertIDs []string
+}
+
+func setupCmdInvestigationGet(cmd *cobra.Command, c *configInvestigationGet) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringSliceVarP(&c.IDs, "id", "I", c.IDs, "Investigation ID. Can be repeated. Default is to read IDs from stdin, one per line.")
+	cmd.Flags().StringSliceVarP(&c.AlertIDs, "alert", "a", c.AlertIDs, "Alert ID, to get the investigation of the alert. Can be repeated.")
+	return cmd
+}
+
+func newCommandInvestigationGet() *cobra.Command {
+	var cmdConfig configInvestigationGet
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get automated investigations by ID, or by alert ID.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var ids []string
+			if len(cmdConfig.AlertIDs) == 0 || len(cmdConfig.IDs) > 0 {
+				var err error
+				if ids, err = readIDs(cmdConfig.IDs); err != nil {
+					return err
+				}
+			}
+			client, err := newClient(investigationConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			for _, alertID := range cmdConfig.AlertIDs {
+				_, alert, err := client.Alert.Get(ctx, alertID)
+				if err != nil {
+					return err
+				}
+				if alert.InvestigationID == nil {
+					return fmt.Errorf("alert %s has no investigation", alertID)
+				}
+				ids = append(ids, strconv.Itoa(*alert.InvestigationID))
+			}
+			for _, id := range ids {
+				_, investigation, err := client.Investigation.Get(ctx, id)
+				if err != nil {
+					return err
+				}
+				if err := writeJSON(investigation); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return setupCmdInvestigationGet(cmd, &cmdConfig)
+}
+
+type configInvestigationStart struct {
+	Comment string
+}
+
+func setupCmdInvestigationStart(cmd *cobra.Command, c *configInvestigationStart) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Comment, "comment", "m", c.Comment, "Comment to associate with the investigation. Required.")
+	cmd.MarkFlagRequired("comment")
+	return cmd
+}
+
+func newCommandInvestigationStart() *cobra.Command {
+	var cmdConfig configInvestigationStart
+	cmd := &cobra.Command{
+		Use:   "start <machineId>...",
+		Short: "Start automated investigations on machines.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(investigationConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, machineID := range args {
+				_, investigation, err := client.Investigation.Start(context.Background(), machineID, cmdConfig.Comment)
+				if err != nil {
+					return err
+				}
+				if err := writeJSON(investigation); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return setupCmdInvestigationStart(cmd, &cmdConfig)
+}
@@ -0,0 +1,334 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	liveResponseConfig configLiveResponseRoot
+)
+
+type configLiveResponseRoot struct {
+	ConfigFile string
+}
+
+func setupCmdLiveResponseRoot(cmd *cobra.Command, c *configLiveResponseRoot) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandLiveResponse() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "liveresponse",
+		Short: "Live response commands.",
+	}
+	cmd.AddCommand(
+		newCommandLiveResponseRun(),
+		newCommandLiveResponseGetFile(),
+		newCommandLiveResponsePutFile(),
+		newCommandLiveResponseLibrary(),
+	)
+	return setupCmdLiveResponseRoot(cmd, &liveResponseConfig)
+}
+
+// configLiveResponse holds the flags shared by
+// the commands running a live response command.
+type configLiveResponse struct {
+	Comment string
+	Output  string
+
+	configMachineActionWait
+}
+
+func setupCmdLiveResponse(cmd *cobra.Command, c *configLiveResponse, outputUsage string) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Comment, "comment", "m", c.Comment, "Comment to associate with the action. Required.")
+	cmd.MarkFlagRequired("comment")
+	if outputUsage != "" {
+		cmd.Flags().StringVarP(&c.Output, "output", "o", c.Output, outputUsage)
+	}
+	setupFlagsMachineActionWait(cmd, &c.configMachineActionWait)
+	return cmd
+}
+
+// liveResponseResult is the JSON document printed
+// once the result of a live response command is downloaded.
+type liveResponseResult struct {
+	MachineAction *mdatp.MachineAction `json:"machineAction"`
+	Result        *fileDownload        `json:"result"`
+}
+
+// runLiveResponse runs command on the machine and waits for it to
+// complete if requested, or if its result must be downloaded to the
+// configured output.
+func runLiveResponse(client *mdatp.Client, machineID string, c *configLiveResponse, command mdatp.LiveResponseCommand) error {
+	wait := c.Wait || c.Output != ""
+	if wait {
+		if err := c.validate(); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+	_, machineAction, err := client.LiveResponse.Run(ctx, machineID, c.Comment, command)
+	if err != nil {
+		return err
+	}
+	if machineAction == nil || mdatp.StringValue(machineAction.ID) == "" {
+		return errors.New("live response machine action returned without ID")
+	}
+	actionID := *machineAction.ID
+	if wait {
+		pollInterval := time.Duration(c.PollInterval) * time.Second
+		_, completed, err := client.MachineAction.WaitForCompletion(ctx, actionID, pollInterval)
+		if err != nil {
+			// the command was started, print its machine action to track it.
+			if err := writeJSON(machineAction); err != nil {
+				return err
+			}
+			return err
+		}
+		machineAction = completed
+	}
+	if c.Output == "" {
+		return writeJSON(machineAction)
+	}
+	if status := mdatp.StringValue(machineAction.Status); status != mdatp.MachineActionStatusSucceeded {
+		if err := writeJSON(machineAction); err != nil {
+			return err
+		}
+		return fmt.Errorf("live response machine action %s completed with status %s", actionID, status)
+	}
+	download, err := downloadToFile(c.Output, func(w io.Writer) (*mdatp.DownloadResult, error) {
+		return client.LiveResponse.DownloadCommandResult(ctx, actionID, 0, w, nil)
+	})
+	if err != nil {
+		// print the machine action so that the download can be retried.
+		if err := writeJSON(machineAction); err != nil {
+			return err
+		}
+		return err
+	}
+	return writeJSON(liveResponseResult{MachineAction: machineAction, Result: download})
+}
+
+type configLiveResponseRun struct {
+	configLiveResponse
+
+	Script string
+	Args   string
+}
+
+func setupCmdLiveResponseRun(cmd *cobra.Command, c *configLiveResponseRun) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Script, "script", "s", c.Script, "Name of the library script to run. Required.")
+	cmd.MarkFlagRequired("script")
+	cmd.Flags().StringVarP(&c.Args, "args", "a", c.Args, "Arguments of the script.")
+	return setupCmdLiveResponse(cmd, &c.configLiveResponse, "Wait for the script to complete and write its output, as JSON, to the provided file.")
+}
+
+func newCommandLiveResponseRun() *cobra.Command {
+	var cmdConfig configLiveResponseRun
+	cmd := &cobra.Command{
+		Use:   "run <machineId>",
+		Short: "Run a script of the live response library on a machine.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(liveResponseConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+			return runLiveResponse(client, args[0], &cmdConfig.configLiveResponse, mdatp.RunScript(cmdConfig.Script, cmdConfig.Args))
+		},
+	}
+	return setupCmdLiveResponseRun(cmd, &cmdConfig)
+}
+
+type configLiveResponseGetFile struct {
+	configLiveResponse
+
+	Path string
+}
+
+func setupCmdLiveResponseGetFile(cmd *cobra.Command, c *configLiveResponseGetFile) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Path, "path", "p", c.Path, "Path of the file to collect on the machine. Required.")
+	cmd.MarkFlagRequired("path")
+	setupCmdLiveResponse(cmd, &c.configLiveResponse, "File to write the collected file to. Required.")
+	cmd.MarkFlagRequired("output")
+	return cmd
+}
+
+func newCommandLiveResponseGetFile() *cobra.Command {
+	var cmdConfig configLiveResponseGetFile
+	cmd := &cobra.Command{
+		Use:   "get-file <machineId>",
+		Short: "Collect a file from a machine.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(liveResponseConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+			return runLiveResponse(client, args[0], &cmdConfig.configLiveResponse, mdatp.GetFile(cmdConfig.Path))
+		},
+	}
+	return setupCmdLiveResponseGetFile(cmd, &cmdConfig)
+}
+
+type configLiveResponsePutFile struct {
+	configLiveResponse
+
+	File string
+}
+
+func setupCmdLiveResponsePutFile(cmd *cobra.Command, c *configLiveResponsePutFile) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.File, "file", "f", c.File, "Name of the library file to copy to the machine. Required.")
+	cmd.MarkFlagRequired("file")
+	return setupCmdLiveResponse(cmd, &c.configLiveResponse, "")
+}
+
+func newCommandLiveResponsePutFile() *cobra.Command {
+	var cmdConfig configLiveResponsePutFile
+	cmd := &cobra.Command{
+		Use:   "put-file <machineId>",
+		Short: "Copy a file of the live response library to a machine.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(liveResponseConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+			return runLiveResponse(client, args[0], &cmdConfig.configLiveResponse, mdatp.PutFile(cmdConfig.File))
+		},
+	}
+	return setupCmdLiveResponsePutFile(cmd, &cmdConfig)
+}
+
+func newCommandLiveResponseLibrary() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "library",
+		Short: "Live response library commands.",
+	}
+	cmd.AddCommand(
+		newCommandLiveResponseLibraryList(),
+		newCommandLiveResponseLibraryUpload(),
+		newCommandLiveResponseLibraryDelete(),
+	)
+	return cmd
+}
+
+func newCommandLiveResponseLibraryList() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the files of the live response library.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(liveResponseConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, files, err := client.LiveResponse.ListLibraryFiles(context.Background())
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(files)
+		},
+	}
+	return cmd
+}
+
+type configLiveResponseLibraryUpload struct {
+	Name                  string
+	Description           string
+	HasParameters         bool
+	ParametersDescription string
+	Override              bool
+}
+
+func setupCmdLiveResponseLibraryUpload(cmd *cobra.Command, c *configLiveResponseLibraryUpload) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Name, "name", "n", c.Name, "Name of the file in the library. Default is the base name of the uploaded file.")
+	cmd.Flags().StringVarP(&c.Description, "description", "d", c.Description, "Description of the file.")
+	cmd.Flags().BoolVar(&c.HasParameters, "has-parameters", c.HasParameters, "Set if the script accepts parameters.")
+	cmd.Flags().StringVar(&c.ParametersDescription, "parameters-description", c.ParametersDescription, "Description of the script parameters.")
+	cmd.Flags().BoolVar(&c.Override, "override", c.Override, "Replace the library file of the same name, if any.")
+	return cmd
+}
+
+func newCommandLiveResponseLibraryUpload() *cobra.Command {
+	var cmdConfig configLiveResponseLibraryUpload
+	cmd := &cobra.Command{
+		Use:   "upload <file>",
+		Short: "Upload a file to the live response library.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := cmdConfig.Name
+			if name == "" {
+				name = filepath.Base(args[0])
+			}
+			f, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			client, err := newClient(liveResponseConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			opts := &mdatp.LibraryFileUploadOptions{
+				Description:           cmdConfig.Description,
+				HasParameters:         cmdConfig.HasParameters,
+				ParametersDescription: cmdConfig.ParametersDescription,
+				OverrideIfExists:      cmdConfig.Override,
+			}
+			_, file, err := client.LiveResponse.UploadLibraryFile(context.Background(), name, f, opts)
+			if err != nil {
+				return err
+			}
+			return writeJSON(file)
+		},
+	}
+	return setupCmdLiveResponseLibraryUpload(cmd, &cmdConfig)
+}
+
+func newCommandLiveResponseLibraryDelete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <fileName>...",
+		Short: "Delete files from the live response library.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(liveResponseConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range args {
+				if _, err := client.LiveResponse.DeleteLibraryFile(context.Background(), name); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
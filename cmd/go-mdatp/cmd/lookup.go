@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sha1Regexp   = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+	sha256Regexp = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+	md5Regexp    = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+	domainRegexp = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9-_]{0,61}[a-zA-Z0-9_])?\.)+[a-zA-Z][a-zA-Z0-9-]{0,62}\.?$`)
+)
+
+// detectIndicatorType returns the type of the provided indicator,
+// among the ones supported by the lookup command.
+func detectIndicatorType(indicator string) (mdatp.IndicatorType, error) {
+	switch {
+	case sha1Regexp.MatchString(indicator):
+		return mdatp.IndicatorTypeFileSha1, nil
+	case sha256Regexp.MatchString(indicator):
+		return mdatp.IndicatorTypeFileSha256, nil
+	case md5Regexp.MatchString(indicator):
+		return "", errors.New("MD5 hashes are not supported by the API, use a SHA1 or SHA256 hash")
+	case net.ParseIP(indicator) != nil:
+		return mdatp.IndicatorTypeIPAddress, nil
+	case domainRegexp.MatchString(indicator):
+		return mdatp.IndicatorTypeDomainName, nil
+	}
+	return "", fmt.Errorf("could not detect the type of %q, must be a SHA1 or SHA256 hash, an IP or a domain", indicator)
+}
+
+// lookupReport is the JSON document printed by the lookup command.
+type lookupReport struct {
+	Indicator string              `json:"indicator"`
+	Type      mdatp.IndicatorType `json:"type"`
+	File      *mdatp.File         `json:"file,omitempty"`
+	Stats     interface{}         `json:"stats"`
+	Alerts    []mdatp.Alert       `json:"alerts"`
+	Machines  []mdatp.Machine     `json:"machines,omitempty"`
+}
+
+type configLookup struct {
+	ConfigFile    string
+	LookBackHours int
+}
+
+func setupCmdLookup(cmd *cobra.Command, c *configLookup) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	cmd.Flags().IntVar(&c.LookBackHours, "lookback-hours", c.LookBackHours, "Number of hours covered by the organization statistics. Default is 30 days.")
+	return cmd
+}
+
+func newCommandLookup() *cobra.Command {
+	var cmdConfig configLookup
+	cmd := &cobra.Command{
+		Use:   "lookup <indicator>",
+		Short: "Print what is known about a file hash, an IP or a domain.",
+		Long: `Print what is known about a file hash, an IP or a domain.
+
+The type of the indicator is detected from its format: SHA1 and SHA256
+file hashes, IPv4 and IPv6 addresses, and domain names are supported.
+A single JSON document is printed, combining the organization statistics,
+the related alerts and, for files and domains, the related machines.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			indicator := strings.TrimSpace(args[0])
+			indicatorType, err := detectIndicatorType(indicator)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(cmdConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			report := lookupReport{Indicator: indicator, Type: indicatorType}
+			switch indicatorType {
+			case mdatp.IndicatorTypeFileSha1, mdatp.IndicatorTypeFileSha256:
+				err = lookupFile(ctx, client, cmdConfig.LookBackHours, &report)
+			case mdatp.IndicatorTypeIPAddress:
+				err = lookupIP(ctx, client, cmdConfig.LookBackHours, &report)
+			case mdatp.IndicatorTypeDomainName:
+				err = lookupDomain(ctx, client, cmdConfig.LookBackHours, &report)
+			}
+			if err != nil {
+				return err
+			}
+			return writeJSON(report)
+		},
+	}
+	return setupCmdLookup(cmd, &cmdConfig)
+}
+
+func lookupFile(ctx context.Context, client *mdatp.Client, lookBackHours int, report *lookupReport) error {
+	var err error
+	// the file may not be known, its statistics are still relevant.
+	if _, report.File, err = client.File.Get(ctx, report.Indicator); err != nil && !errors.Is(err, mdatp.ErrNotFound) {
+		return err
+	}
+	_, stats, err := client.File.Stats(ctx, report.Indicator, lookBackHours)
+	if err != nil {
+		return err
+	}
+	report.Stats = stats
+	if _, report.Alerts, err = client.File.ListAlerts(ctx, report.Indicator); err != nil {
+		return err
+	}
+	_, report.Machines, err = client.File.ListMachines(ctx, report.Indicator)
+	return err
+}
+
+func lookupIP(ctx context.Context, client *mdatp.Client, lookBackHours int, report *lookupReport) error {
+	_, stats, err := client.IP.Stats(ctx, report.Indicator, lookBackHours)
+	if err != nil {
+		return err
+	}
+	report.Stats = stats
+	_, report.Alerts, err = client.IP.ListAlerts(ctx, report.Indicator)
+	return err
+}
+
+func lookupDomain(ctx context.Context, client *mdatp.Client, lookBackHours int, report *lookupReport) error {
+	_, stats, err := client.Domain.Stats(ctx, report.Indicator, lookBackHours)
+	if err != nil {
+		return err
+	}
+	report.Stats = stats
+	if _, report.Alerts, err = client.Domain.ListAlerts(ctx, report.Indicator); err != nil {
+		return err
+	}
+	_, report.Machines, err = client.Domain.ListMachines(ctx, report.Indicator)
+	return err
+}
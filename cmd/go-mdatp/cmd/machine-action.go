@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+// machineActionFunc submits a machine action using the provided config.
+type machineActionFunc func(ctx context.Context, client *mdatp.Client, machineID string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error)
+
+type configMachineAction struct {
+	Comment string
+	Type    string
+
+	configMachineActionWait
+}
+
+// configMachineActionWait holds the flags of commands
+// that can wait for machine actions to complete.
+type configMachineActionWait struct {
+	Wait bool
+	// PollInterval is the duration, in seconds, between two machine action status checks.
+	PollInterval int
+}
+
+func setupCmdMachineAction(cmd *cobra.Command, c *configMachineAction, typeUsage string) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Comment, "comment", "m", c.Comment, "Comment to associate with the action. Required.")
+	cmd.MarkFlagRequired("comment")
+	if typeUsage != "" {
+		cmd.Flags().StringVarP(&c.Type, "type", "t", c.Type, typeUsage)
+	}
+	setupFlagsMachineActionWait(cmd, &c.configMachineActionWait)
+	return cmd
+}
+
+// validate returns an error if the poll interval is invalid,
+// which must be checked before any machine action is submitted.
+func (c *configMachineActionWait) validate() error {
+	return mdatp.ValidatePollInterval(time.Duration(c.PollInterval) * time.Second)
+}
+
+func setupFlagsMachineActionWait(cmd *cobra.Command, c *configMachineActionWait) {
+	cmd.Flags().BoolVarP(&c.Wait, "wait", "w", c.Wait, "Wait for the actions to complete and print their final state.")
+	cmd.Flags().IntVar(&c.PollInterval, "poll-interval", c.PollInterval, "Sets the interval, in seconds, at which to check the status of the actions when waiting. Default is 3 seconds.")
+}
+
+// writeMachineActions writes each machine action as is.
+func writeMachineActions(machineActions []*mdatp.MachineAction) error {
+	for _, machineAction := range machineActions {
+		if err := writeJSON(machineAction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submitMachineActions calls submit for every ID and returns the machine
+// actions submitted. On error, the machine actions already submitted are
+// written so that they can be tracked or cancelled.
+func submitMachineActions(ids []string, submit func(id string) (*mdatp.MachineAction, error)) ([]*mdatp.MachineAction, error) {
+	var machineActions []*mdatp.MachineAction
+	for _, id := range ids {
+		machineAction, err := submit(id)
+		if err == nil && (machineAction == nil || mdatp.StringValue(machineAction.ID) == "") {
+			err = errors.New("machine action returned without ID")
+		}
+		if err != nil {
+			if err := writeMachineActions(machineActions); err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		machineActions = append(machineActions, machineAction)
+	}
+	return machineActions, nil
+}
+
+// waitMachineActions waits, if requested, for each machine action
+// to complete and writes their state once completed. Otherwise,
+// machine actions are written as is. If waiting fails, the machine
+// actions not yet written are written as is before returning the error.
+func waitMachineActions(ctx context.Context, client *mdatp.Client, c *configMachineActionWait, machineActions []*mdatp.MachineAction) error {
+	pollInterval := time.Duration(c.PollInterval) * time.Second
+	for i, machineAction := range machineActions {
+		if c.Wait {
+			_, completed, err := client.MachineAction.WaitForCompletion(ctx, mdatp.StringValue(machineAction.ID), pollInterval)
+			if err != nil {
+				if err := writeMachineActions(machineActions[i:]); err != nil {
+					return err
+				}
+				return err
+			}
+			machineAction = completed
+		}
+		if err := writeJSON(machineAction); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newCommandMachineAction returns a command submitting an action
+// against every machine ID provided as argument, then waiting for
+// them to complete if requested. The type flag is only registered
+// when typeUsage is not empty.
+func newCommandMachineAction(use, short, typeUsage string, action machineActionFunc) *cobra.Command {
+	var cmdConfig configMachineAction
+	cmd := &cobra.Command{
+		Use:   use + " <id>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmdConfig.Wait {
+				if err := cmdConfig.validate(); err != nil {
+					return err
+				}
+			}
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			machineActions, err := submitMachineActions(args, func(id string) (*mdatp.MachineAction, error) {
+				_, machineAction, err := action(ctx, client, id, &cmdConfig)
+				return machineAction, err
+			})
+			if err != nil {
+				return err
+			}
+			return waitMachineActions(ctx, client, &cmdConfig.configMachineActionWait, machineActions)
+		},
+	}
+	return setupCmdMachineAction(cmd, &cmdConfig, typeUsage)
+}
+
+func newCommandMachineIsolate() *cobra.Command {
+	return newCommandMachineAction(
+		"isolate",
+		"Isolate machines from the network.",
+		"Isolation type. One of: Full, Selective. Default is Full.",
+		func(ctx context.Context, client *mdatp.Client, id string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error) {
+			isolationType := mdatp.IsolationTypeFull
+			if c.Type != "" {
+				isolationType = mdatp.IsolationType(c.Type)
+			}
+			return client.MachineAction.Isolate(ctx, id, c.Comment, isolationType)
+		},
+	)
+}
+
+func newCommandMachineRelease() *cobra.Command {
+	return newCommandMachineAction(
+		"release",
+		"Release machines from isolation.",
+		"",
+		func(ctx context.Context, client *mdatp.Client, id string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error) {
+			return client.MachineAction.Unisolate(ctx, id, c.Comment)
+		},
+	)
+}
+
+func newCommandMachineScan() *cobra.Command {
+	return newCommandMachineAction(
+		"scan",
+		"Run an antivirus scan on machines.",
+		"Scan type. One of: Quick, Full. Default is Quick.",
+		func(ctx context.Context, client *mdatp.Client, id string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error) {
+			scanType := mdatp.ScanTypeQuick
+			if c.Type != "" {
+				scanType = mdatp.ScanType(c.Type)
+			}
+			return client.MachineAction.RunAntiVirusScan(ctx, id, c.Comment, scanType)
+		},
+	)
+}
+
+func newCommandMachineCollect() *cobra.Command {
+	return newCommandMachineAction(
+		"collect",
+		"Collect an investigation package from machines.",
+		"",
+		func(ctx context.Context, client *mdatp.Client, id string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error) {
+			return client.MachineAction.CollectInvestigationPackage(ctx, id, c.Comment)
+		},
+	)
+}
+
+func newCommandMachineRestrict() *cobra.Command {
+	return newCommandMachineAction(
+		"restrict",
+		"Restrict code execution on machines.",
+		"",
+		func(ctx context.Context, client *mdatp.Client, id string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error) {
+			return client.MachineAction.RestrictCodeExecution(ctx, id, c.Comment)
+		},
+	)
+}
+
+func newCommandMachineUnrestrict() *cobra.Command {
+	return newCommandMachineAction(
+		"unrestrict",
+		"Remove code execution restrictions on machines.",
+		"",
+		func(ctx context.Context, client *mdatp.Client, id string, c *configMachineAction) (*mdatp.Response, *mdatp.MachineAction, error) {
+			return client.MachineAction.UnrestrictCodeExecution(ctx, id, c.Comment)
+		},
+	)
+}
+
+func newCommandMachineActionRoot() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "action",
+		Short: "Machine action resource type commands.",
+	}
+	cmd.AddCommand(
+		newCommandMachineActionList(),
+		newCommandMachineActionGet(),
+		newCommandMachineActionCancel(),
+	)
+	return cmd
+}
+
+type configMachineActionList struct {
+	ODataQueryFilter string
+}
+
+func setupCmdMachineActionList(cmd *cobra.Command, c *configMachineActionList) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.ODataQueryFilter, "query-filter", "f", c.ODataQueryFilter, "$filter OData V4 query option string.")
+	return cmd
+}
+
+func newCommandMachineActionList() *cobra.Command {
+	var cmdConfig configMachineActionList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List machine actions.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.MachineAction.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.MachineActionListResponse) error {
+				for _, a := range page.Value {
+					if err := writeJSON(a); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+			return err
+		},
+	}
+	return setupCmdMachineActionList(cmd, &cmdConfig)
+}
+
+type configMachineActionGet struct {
+	configMachineActionWait
+}
+
+func setupCmdMachineActionGet(cmd *cobra.Command, c *configMachineActionGet) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	setupFlagsMachineActionWait(cmd, &c.configMachineActionWait)
+	return cmd
+}
+
+func newCommandMachineActionGet() *cobra.Command {
+	var cmdConfig configMachineActionGet
+	cmd := &cobra.Command{
+		Use:   "get <id>...",
+		Short: "Get machine actions by ID.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			var machineActions []*mdatp.MachineAction
+			for _, id := range args {
+				_, machineAction, err := client.MachineAction.Get(ctx, id)
+				if err != nil {
+					return err
+				}
+				machineActions = append(machineActions, machineAction)
+			}
+			return waitMachineActions(ctx, client, &cmdConfig.configMachineActionWait, machineActions)
+		},
+	}
+	return setupCmdMachineActionGet(cmd, &cmdConfig)
+}
+
+func newCommandMachineActionCancel() *cobra.Command {
+	var cmdConfig configMachineAction
+	cmd := &cobra.Command{
+		Use:   "cancel <id>...",
+		Short: "Cancel machine actions that are not yet completed.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmdConfig.Wait {
+				if err := cmdConfig.validate(); err != nil {
+					return err
+				}
+			}
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			machineActions, err := submitMachineActions(args, func(id string) (*mdatp.MachineAction, error) {
+				_, machineAction, err := client.MachineAction.Cancel(ctx, id, cmdConfig.Comment)
+				return machineAction, err
+			})
+			if err != nil {
+				return err
+			}
+			return waitMachineActions(ctx, client, &cmdConfig.configMachineActionWait, machineActions)
+		},
+	}
+	return setupCmdMachineAction(cmd, &cmdConfig, "")
+}
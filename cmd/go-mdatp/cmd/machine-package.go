@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"go-mdatp/pkg/mdatp"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+func newCommandMachinePackage() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "package",
+		Short: "Investigation package commands.",
+	}
+	cmd.AddCommand(
+		newCommandMachinePackageDownload(),
+	)
+	return cmd
+}
+
+type configMachinePackageDownload struct {
+	Output string
+	SHA256 string
+}
+
+func setupCmdMachinePackageDownload(cmd *cobra.Command, c *configMachinePackageDownload) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Output, "output", "o", c.Output, "File to write the package to. Required.")
+	cmd.MarkFlagRequired("output")
+	cmd.Flags().StringVar(&c.SHA256, "sha256", c.SHA256, "Expected SHA-256 digest of the package, hex encoded.")
+	return cmd
+}
+
+// fileDownload is the JSON document printed once a file is downloaded.
+type fileDownload struct {
+	File string `json:"file"`
+	*mdatp.DownloadResult
+}
+
+// downloadToFile calls download with the file at path, created or
+// truncated. The file is removed if the download fails.
+func downloadToFile(path string, download func(w io.Writer) (*mdatp.DownloadResult, error)) (*fileDownload, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return nil, err
+	}
+	result, err := download(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return &fileDownload{File: path, DownloadResult: result}, nil
+}
+
+func newCommandMachinePackageDownload() *cobra.Command {
+	var cmdConfig configMachinePackageDownload
+	cmd := &cobra.Command{
+		Use:   "download <actionId>",
+		Short: "Download the investigation package collected by a machine action.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			opts := &mdatp.DownloadOptions{ExpectedSHA256: cmdConfig.SHA256}
+			download, err := downloadToFile(cmdConfig.Output, func(w io.Writer) (*mdatp.DownloadResult, error) {
+				return client.MachineAction.DownloadPackage(context.Background(), args[0], w, opts)
+			})
+			if err != nil {
+				return err
+			}
+			return writeJSON(download)
+		},
+	}
+	return setupCmdMachinePackageDownload(cmd, &cmdConfig)
+}
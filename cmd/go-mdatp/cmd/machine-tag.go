@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+func newCommandMachineTag() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tag",
+		Short: "Add or remove machine tags.",
+	}
+	cmd.AddCommand(
+		newCommandMachineTagAction("add", "Add a tag to machines.", mdatp.MachineTagActionAdd),
+		newCommandMachineTagAction("remove", "Remove a tag from machines.", mdatp.MachineTagActionRemove),
+	)
+	return cmd
+}
+
+type configMachineTag struct {
+	Tag              string
+	ODataQueryFilter string
+}
+
+func setupCmdMachineTag(cmd *cobra.Command, c *configMachineTag) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Tag, "tag", "t", c.Tag, "Tag to add or remove. Required.")
+	cmd.MarkFlagRequired("tag")
+	cmd.Flags().StringVarP(&c.ODataQueryFilter, "query-filter", "f", c.ODataQueryFilter, "$filter OData V4 query option string, selecting the machines instead of IDs.")
+	return cmd
+}
+
+// machineTagResult is the JSON document printed by the machine tag commands.
+type machineTagResult struct {
+	Tag        string                 `json:"tag"`
+	Action     mdatp.MachineTagAction `json:"action"`
+	MachineIDs []string               `json:"machineIds"`
+}
+
+// machineIDs returns the IDs of the machines selected by the
+// query filter if any, otherwise the provided IDs or the ones
+// read from stdin.
+func (c *configMachineTag) machineIDs(ctx context.Context, client *mdatp.Client, ids []string) ([]string, error) {
+	if c.ODataQueryFilter == "" {
+		return readIDs(ids)
+	}
+	if len(ids) > 0 {
+		return nil, errors.New("machine IDs cannot be combined with a query filter")
+	}
+	_, machines, err := client.Machine.ListAll(ctx, c.ODataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range machines {
+		if id := mdatp.StringValue(m.ID); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// newCommandMachineTagAction returns a command applying action with
+// the tag to every selected machine, using the bulk endpoint.
+func newCommandMachineTagAction(use, short string, action mdatp.MachineTagAction) *cobra.Command {
+	var cmdConfig configMachineTag
+	cmd := &cobra.Command{
+		Use:   use + " [id]...",
+		Short: short,
+		Long: short + `
+
+Machines are selected by ID, provided as arguments or read from stdin,
+one per line, or using an OData filter. Requests are split to respect
+the API limit of machines per call. If a request fails, the machines
+changed by the previous requests are printed before the error.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			ids, err := cmdConfig.machineIDs(ctx, client, args)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return writeJSON(machineTagResult{Tag: cmdConfig.Tag, Action: action, MachineIDs: ids})
+			}
+			_, applied, err := client.Machine.AddOrRemoveTagForMultipleMachines(ctx, ids, cmdConfig.Tag, action)
+			if err != nil {
+				// report the machines already changed by the previous requests.
+				if applied > 0 {
+					if err := writeJSON(machineTagResult{Tag: cmdConfig.Tag, Action: action, MachineIDs: ids[:applied]}); err != nil {
+						return err
+					}
+				}
+				return fmt.Errorf("tag applied to %d of %d machines: %w", applied, len(ids), err)
+			}
+			return writeJSON(machineTagResult{Tag: cmdConfig.Tag, Action: action, MachineIDs: ids})
+		},
+	}
+	return setupCmdMachineTag(cmd, &cmdConfig)
+}
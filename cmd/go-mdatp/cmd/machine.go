@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"context"
+	"go-mdatp/pkg/mdatp"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	machineConfig configMachine
+)
+
+type configMachine struct {
+	ConfigFile string
+}
+
+func setupCmdMachine(cmd *cobra.Command, c *configMachine) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandMachine() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "machine",
+		Short: "Machine resource type commands.",
+	}
+	cmd.AddCommand(
+		newCommandMachineList(),
+		newCommandMachineGet(),
+		newCommandMachineLogonUsers(),
+		newCommandMachineAlerts(),
+		newCommandMachineFindByIP(),
+		newCommandMachineFindByTag(),
+		newCommandMachineIsolate(),
+		newCommandMachineRelease(),
+		newCommandMachineScan(),
+		newCommandMachineCollect(),
+		newCommandMachineRestrict(),
+		newCommandMachineUnrestrict(),
+		newCommandMachineActionRoot(),
+		newCommandMachinePackage(),
+		newCommandMachineTag(),
+	)
+	return setupCmdMachine(cmd, &machineConfig)
+}
+
+// writeMachines writes each machine as a JSON line.
+func writeMachines(machines []mdatp.Machine) error {
+	for _, m := range machines {
+		if err := writeJSON(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type configMachineList struct {
+	ODataQueryFilter string
+}
+
+func setupCmdMachineList(cmd *cobra.Command, c *configMachineList) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.ODataQueryFilter, "query-filter", "f", c.ODataQueryFilter, "$filter OData V4 query option string.")
+	return cmd
+}
+
+func newCommandMachineList() *cobra.Command {
+	var cmdConfig configMachineList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List machines.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Machine.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.MachineListResponse) error {
+				return writeMachines(page.Value)
+			})
+			return err
+		},
+	}
+	return setupCmdMachineList(cmd, &cmdConfig)
+}
+
+type configMachineGet struct {
+	IDs []string
+}
+
+func setupCmdMachineGet(cmd *cobra.Command, c *configMachineGet) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringSliceVarP(&c.IDs, "id", "I", c.IDs, "Machine ID. Can be repeated. Default is to read IDs from stdin, one per line.")
+	return cmd
+}
+
+func newCommandMachineGet() *cobra.Command {
+	var cmdConfig configMachineGet
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get machines by ID.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := readIDs(cmdConfig.IDs)
+			if err != nil {
+				return err
+			}
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range ids {
+				_, machine, err := client.Machine.Get(context.Background(), id)
+				if err != nil {
+					return err
+				}
+				if err := writeJSON(machine); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return setupCmdMachineGet(cmd, &cmdConfig)
+}
+
+func newCommandMachineLogonUsers() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logon-users <id>",
+		Short: "List the users who logged on to a machine.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, users, err := client.Machine.ListLogonUsers(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+			for _, u := range users {
+				if err := writeJSON(u); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+func newCommandMachineAlerts() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "alerts <id>",
+		Short: "List the alerts related to a machine.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, alerts, err := client.Machine.ListAlerts(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+			for _, a := range alerts {
+				if err := writeJSON(a); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+type configMachineFindByIP struct {
+	Timestamp string
+}
+
+func setupCmdMachineFindByIP(cmd *cobra.Command, c *configMachineFindByIP) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Timestamp, "timestamp", "t", c.Timestamp, "UTC time at which the IP was seen, as YYYY-MM-DD[THH:MM[:SS]]. Default is now.")
+	return cmd
+}
+
+func newCommandMachineFindByIP() *cobra.Command {
+	var cmdConfig configMachineFindByIP
+	cmd := &cobra.Command{
+		Use:   "find-by-ip <ip>",
+		Short: "Find machines seen with an internal IP, 15 minutes before or after a timestamp.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			timestamp := time.Now()
+			if cmdConfig.Timestamp != "" {
+				var err error
+				if timestamp, err = parseDate(cmdConfig.Timestamp); err != nil {
+					return err
+				}
+			}
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, machines, err := client.Machine.FindByIP(context.Background(), args[0], timestamp)
+			if err != nil {
+				return err
+			}
+			return writeMachines(machines)
+		},
+	}
+	return setupCmdMachineFindByIP(cmd, &cmdConfig)
+}
+
+type configMachineFindByTag struct {
+	StartsWith bool
+}
+
+func setupCmdMachineFindByTag(cmd *cobra.Command, c *configMachineFindByTag) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().BoolVar(&c.StartsWith, "starts-with", c.StartsWith, "Find machines having a tag starting with the provided value.")
+	return cmd
+}
+
+func newCommandMachineFindByTag() *cobra.Command {
+	var cmdConfig configMachineFindByTag
+	cmd := &cobra.Command{
+		Use:   "find-by-tag <tag>",
+		Short: "Find machines by tag.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(machineConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, machines, err := client.Machine.FindByTag(context.Background(), args[0], cmdConfig.StartsWith)
+			if err != nil {
+				return err
+			}
+			return writeMachines(machines)
+		},
+	}
+	return setupCmdMachineFindByTag(cmd, &cmdConfig)
+}
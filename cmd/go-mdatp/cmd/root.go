@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -12,6 +19,7 @@ import (
 var (
 	defaultLoggerOutput = os.Stderr
 	defaultOutput       = os.Stdout
+	defaultInput        = os.Stdin
 
 	timeFormats = []string{
 		"2006-01-02",
@@ -32,6 +40,61 @@ func writeOut(s string) {
 	fmt.Fprintln(defaultOutput, s)
 }
 
+// writeJSON writes the JSON encoding of v as a single line.
+func writeJSON(v interface{}) error {
+	marshalled, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	writeOut(string(marshalled))
+	return nil
+}
+
+// writeJSONLines writes each element of the slice v as a JSON line.
+// An error is returned if v is not a slice.
+func writeJSONLines(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("cannot write %T as JSON lines", v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := writeJSON(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readIDs returns ids if any, otherwise it reads IDs from
+// defaultInput, one per line, skipping blank lines.
+func readIDs(ids []string) ([]string, error) {
+	if len(ids) > 0 {
+		return ids, nil
+	}
+	scanner := bufio.NewScanner(defaultInput)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no IDs provided using flags or stdin")
+	}
+	return ids, nil
+}
+
+// openInput opens the provided file for reading,
+// or defaultInput if path is empty or "-".
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "" || path == "-" {
+		return ioutil.NopCloser(defaultInput), nil
+	}
+	return os.Open(path)
+}
+
 func newCommandRoot() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "go-mdatp",
@@ -41,6 +104,16 @@ func newCommandRoot() *cobra.Command {
 	cmd.AddCommand(
 		newCommandGenDoc(),
 		newCommandAlert(),
+		newCommandMachine(),
+		newCommandIndicator(),
+		newCommandHunt(),
+		newCommandFile(),
+		newCommandInvestigation(),
+		newCommandLiveResponse(),
+		newCommandLookup(),
+		newCommandScore(),
+		newCommandTVM(),
+		newCommandUser(),
 	)
 	return cmd
 }
@@ -79,6 +152,26 @@ func initConfig(cfgFile string) (*Config, error) {
 	return &config, nil
 }
 
+// newClient loads the configuration file and creates a Client
+// authenticated using its credentials. The provided options are
+// applied after the default ones.
+func newClient(cfgFile string, opts ...mdatp.ClientOption) (*mdatp.Client, error) {
+	config, err := initConfig(cfgFile)
+	if err != nil {
+		return nil, err
+	}
+	defaultOpts := []mdatp.ClientOption{
+		mdatp.WithOAuthClient(
+			config.Credentials.ClientID,
+			config.Credentials.ClientSecret,
+			config.Credentials.TenantID,
+		),
+		mdatp.WithRetryPolicy(mdatp.DefaultRetryPolicy),
+		mdatp.WithRateLimit(mdatp.DefaultRateLimitPerMinute, mdatp.DefaultRateLimitPerHour),
+	}
+	return mdatp.NewClient(append(defaultOpts, opts...)...)
+}
+
 // Config stores credentials and application
 // specific attributes.
 type Config struct {
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"go-mdatp/pkg/mdatp"
+	"os"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scoreConfig configScore
+)
+
+type configScore struct {
+	ConfigFile string
+}
+
+func setupCmdScoreRoot(cmd *cobra.Command, c *configScore) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+// scoreSnapshot is the JSON document printed by the score command.
+type scoreSnapshot struct {
+	Time                         time.Time     `json:"time"`
+	ExposureScore                *mdatp.Score  `json:"exposureScore"`
+	ExposureScoreByMachineGroups []mdatp.Score `json:"exposureScoreByMachineGroups"`
+	ConfigurationScore           *mdatp.Score  `json:"configurationScore"`
+}
+
+type configScoreShow struct {
+	History string
+}
+
+func setupCmdScore(cmd *cobra.Command, c *configScoreShow) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVar(&c.History, "history", c.History, "Append the scores, as a JSON line, to the provided history file.")
+	return cmd
+}
+
+func newCommandScore() *cobra.Command {
+	var cmdConfig configScoreShow
+	cmd := &cobra.Command{
+		Use:   "score",
+		Short: "Print the exposure and secure scores of the organization.",
+		Long: `Print the exposure score of the organization and of each machine group,
+and the device secure score, as a single JSON document.
+
+Using --history, the same document is appended to a JSON lines file,
+to keep track of the scores over time.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(scoreConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			snapshot := scoreSnapshot{Time: time.Now().UTC()}
+			if _, snapshot.ExposureScore, err = client.Score.GetExposureScore(ctx); err != nil {
+				return err
+			}
+			if _, snapshot.ExposureScoreByMachineGroups, err = client.Score.ListExposureScoreByMachineGroups(ctx); err != nil {
+				return err
+			}
+			if _, snapshot.ConfigurationScore, err = client.Score.GetConfigurationScore(ctx); err != nil {
+				return err
+			}
+			if cmdConfig.History != "" {
+				if err := appendJSONLine(cmdConfig.History, snapshot); err != nil {
+					return err
+				}
+			}
+			return writeJSON(snapshot)
+		},
+	}
+	cmd.AddCommand(
+		newCommandScoreSetDeviceValue(),
+	)
+	setupCmdScore(cmd, &cmdConfig)
+	return setupCmdScoreRoot(cmd, &scoreConfig)
+}
+
+// appendJSONLine appends the JSON encoding of v
+// as a single line to the provided file.
+func appendJSONLine(path string, v interface{}) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(v); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type configScoreSetDeviceValue struct {
+	Value string
+}
+
+func setupCmdScoreSetDeviceValue(cmd *cobra.Command, c *configScoreSetDeviceValue) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Value, "value", "v", c.Value, "Device value: Low, Normal or High. Required.")
+	cmd.MarkFlagRequired("value")
+	return cmd
+}
+
+func newCommandScoreSetDeviceValue() *cobra.Command {
+	var cmdConfig configScoreSetDeviceValue
+	cmd := &cobra.Command{
+		Use:   "set-device-value <machineId>...",
+		Short: "Set the value of machines, used to weight their exposure.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(scoreConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, machineID := range args {
+				_, machine, err := client.Score.SetDeviceValue(context.Background(), machineID, mdatp.DeviceValue(cmdConfig.Value))
+				if err != nil {
+					return err
+				}
+				if err := writeJSON(machine); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return setupCmdScoreSetDeviceValue(cmd, &cmdConfig)
+}
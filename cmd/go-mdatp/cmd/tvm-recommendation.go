@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"go-mdatp/pkg/mdatp"
+	"strings"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// recommendationSeverities maps severity names to the minimum
+	// severity score of a recommendation, as used by CVSS v3.
+	recommendationSeverities = map[string]float64{
+		"low":      0.1,
+		"medium":   4,
+		"high":     7,
+		"critical": 9,
+	}
+)
+
+func newCommandTVMRecommendation() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "recommendation",
+		Aliases: []string{"recommendations"},
+		Short:   "Security recommendation commands.",
+	}
+	cmd.AddCommand(
+		newCommandTVMRecommendationList(),
+		newCommandTVMByID("get", "Get security recommendations by ID.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, recommendation, err := client.Recommendation.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSON(recommendation)
+		}),
+		newCommandTVMByID("software", "List the software a security recommendation is about.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, software, err := client.Recommendation.ListSoftware(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(software)
+		}),
+		newCommandTVMByID("machines", "List the machines exposed to a security recommendation.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, machines, err := client.Recommendation.ListMachineReferences(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(machines)
+		}),
+		newCommandTVMByID("vulnerabilities", "List the vulnerabilities addressed by a security recommendation.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, vulnerabilities, err := client.Recommendation.ListVulnerabilities(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(vulnerabilities)
+		}),
+	)
+	return cmd
+}
+
+type configTVMRecommendationList struct {
+	configTVMList
+
+	Severity      string
+	PublicExploit bool
+}
+
+func setupCmdTVMRecommendationList(cmd *cobra.Command, c *configTVMRecommendationList) *cobra.Command {
+	envconfig.Process("", c)
+	setupCmdTVMList(cmd, &c.configTVMList)
+	cmd.Flags().StringVarP(&c.Severity, "severity", "s", c.Severity, "Only list recommendations of at least this severity: low, medium, high or critical.")
+	cmd.Flags().BoolVarP(&c.PublicExploit, "public-exploit", "e", c.PublicExploit, "Only list recommendations with a publicly available exploit.")
+	return cmd
+}
+
+// odataQueryFilter returns the $filter query option combining
+// the provided filter with the severity and public exploit flags.
+func (c *configTVMRecommendationList) odataQueryFilter() (string, error) {
+	var conditions []string
+	if c.ODataQueryFilter != "" {
+		conditions = append(conditions, fmt.Sprintf("(%s)", c.ODataQueryFilter))
+	}
+	if c.Severity != "" {
+		score, ok := recommendationSeverities[strings.ToLower(c.Severity)]
+		if !ok {
+			return "", fmt.Errorf("invalid severity %q, must be one of: low, medium, high, critical", c.Severity)
+		}
+		conditions = append(conditions, fmt.Sprintf("severityScore ge %v", score))
+	}
+	if c.PublicExploit {
+		conditions = append(conditions, "publicExploit eq true")
+	}
+	return strings.Join(conditions, " and "), nil
+}
+
+func newCommandTVMRecommendationList() *cobra.Command {
+	var cmdConfig configTVMRecommendationList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List security recommendations.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := cmdConfig.odataQueryFilter()
+			if err != nil {
+				return err
+			}
+			client, err := newClient(tvmConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Recommendation.ListPages(context.Background(), filter, func(page *mdatp.RecommendationListResponse) error {
+				return writeJSONLines(page.Value)
+			})
+			return err
+		},
+	}
+	return setupCmdTVMRecommendationList(cmd, &cmdConfig)
+}
+
+func newCommandTVMRemediation() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remediation",
+		Aliases: []string{"remediations"},
+		Short:   "Remediation activity commands.",
+	}
+	cmd.AddCommand(
+		newCommandTVMRemediationList(),
+		newCommandTVMByID("get", "Get remediation activities by ID.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, activity, err := client.Remediation.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSON(activity)
+		}),
+		newCommandTVMByID("machines", "List the machines exposed to a remediation activity.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, machines, err := client.Remediation.ListMachineReferences(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(machines)
+		}),
+	)
+	return cmd
+}
+
+func newCommandTVMRemediationList() *cobra.Command {
+	var cmdConfig configTVMList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List remediation activities.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(tvmConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Remediation.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.RemediationActivityListResponse) error {
+				return writeJSONLines(page.Value)
+			})
+			return err
+		},
+	}
+	return setupCmdTVMList(cmd, &cmdConfig)
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"go-mdatp/pkg/mdatp"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tvmConfig configTVM
+)
+
+type configTVM struct {
+	ConfigFile string
+}
+
+func setupCmdTVM(cmd *cobra.Command, c *configTVM) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandTVM() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tvm",
+		Short: "Threat & Vulnerability Management commands.",
+	}
+	cmd.AddCommand(
+		newCommandTVMSoftware(),
+		newCommandTVMVulnerability(),
+		newCommandTVMMachine(),
+		newCommandTVMRecommendation(),
+		newCommandTVMRemediation(),
+	)
+	return setupCmdTVM(cmd, &tvmConfig)
+}
+
+// tvmFunc retrieves and writes the resources related to the provided ID.
+type tvmFunc func(ctx context.Context, client *mdatp.Client, id string) error
+
+// newCommandTVMByID returns a command calling fn for every ID provided as argument.
+func newCommandTVMByID(use, short string, fn tvmFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use + " <id>...",
+		Short: short,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(tvmConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				if err := fn(context.Background(), client, id); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+	return cmd
+}
+
+type configTVMList struct {
+	ODataQueryFilter string
+}
+
+func setupCmdTVMList(cmd *cobra.Command, c *configTVMList) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.ODataQueryFilter, "query-filter", "f", c.ODataQueryFilter, "$filter OData V4 query option string.")
+	return cmd
+}
+
+func newCommandTVMSoftware() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "software",
+		Short: "Software inventory commands.",
+	}
+	cmd.AddCommand(
+		newCommandTVMSoftwareList(),
+		newCommandTVMByID("get", "Get software by ID.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, software, err := client.Software.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSON(software)
+		}),
+		newCommandTVMByID("distributions", "List the versions of a software installed in the organization.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, distributions, err := client.Software.ListDistributions(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(distributions)
+		}),
+		newCommandTVMByID("machines", "List the machines on which a software is installed.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, machines, err := client.Software.ListMachineReferences(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(machines)
+		}),
+		newCommandTVMByID("vulnerabilities", "List the vulnerabilities of a software.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, vulnerabilities, err := client.Software.ListVulnerabilities(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(vulnerabilities)
+		}),
+	)
+	return cmd
+}
+
+func newCommandTVMSoftwareList() *cobra.Command {
+	var cmdConfig configTVMList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List software.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(tvmConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Software.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.SoftwareListResponse) error {
+				return writeJSONLines(page.Value)
+			})
+			return err
+		},
+	}
+	return setupCmdTVMList(cmd, &cmdConfig)
+}
+
+func newCommandTVMVulnerability() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "vulnerability",
+		Aliases: []string{"vulnerabilities"},
+		Short:   "Vulnerability commands.",
+	}
+	cmd.AddCommand(
+		newCommandTVMVulnerabilityList(),
+		newCommandTVMByID("get", "Get vulnerabilities by ID.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, vulnerability, err := client.Vulnerability.Get(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSON(vulnerability)
+		}),
+		newCommandTVMByID("machines", "List the machines exposed to a vulnerability.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, machines, err := client.Vulnerability.ListMachineReferences(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(machines)
+		}),
+	)
+	return cmd
+}
+
+func newCommandTVMVulnerabilityList() *cobra.Command {
+	var cmdConfig configTVMList
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List vulnerabilities.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient(tvmConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			_, err = client.Vulnerability.ListPages(context.Background(), cmdConfig.ODataQueryFilter, func(page *mdatp.VulnerabilityListResponse) error {
+				return writeJSONLines(page.Value)
+			})
+			return err
+		},
+	}
+	return setupCmdTVMList(cmd, &cmdConfig)
+}
+
+func newCommandTVMMachine() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "machine",
+		Short: "Machine software, vulnerabilities and recommendations commands.",
+	}
+	cmd.AddCommand(
+		newCommandTVMByID("software", "List the software installed on machines.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, software, err := client.Machine.ListSoftware(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(software)
+		}),
+		newCommandTVMByID("vulnerabilities", "List the vulnerabilities machines are exposed to.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, vulnerabilities, err := client.Machine.ListVulnerabilities(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(vulnerabilities)
+		}),
+		newCommandTVMByID("recommendations", "List the security recommendations of machines.", func(ctx context.Context, client *mdatp.Client, id string) error {
+			_, recommendations, err := client.Machine.ListRecommendations(ctx, id)
+			if err != nil {
+				return err
+			}
+			return writeJSONLines(recommendations)
+		}),
+	)
+	return cmd
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"go-mdatp/pkg/mdatp"
+	"sort"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/spf13/cobra"
+)
+
+var (
+	userConfig configUser
+)
+
+type configUser struct {
+	ConfigFile string
+}
+
+func setupCmdUser(cmd *cobra.Command, c *configUser) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.PersistentFlags().SortFlags = false
+	cmd.PersistentFlags().StringVarP(&c.ConfigFile, "config", "c", c.ConfigFile, "config file (default is $CWD/.go-mdatp.yaml)")
+	return cmd
+}
+
+func newCommandUser() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "user",
+		Short: "User resource type commands.",
+	}
+	cmd.AddCommand(
+		newCommandUserShow(),
+	)
+	return setupCmdUser(cmd, &userConfig)
+}
+
+// userReport is the JSON document printed by the user show command.
+type userReport struct {
+	Account  string        `json:"account"`
+	Alerts   []mdatp.Alert `json:"alerts"`
+	Machines []userMachine `json:"machines"`
+	// AssignedTo counts the alerts by assignee, unassigned
+	// alerts being counted under an empty assignee.
+	AssignedTo map[string]int `json:"assignedTo"`
+	// Commenters counts the comments on the alerts by author.
+	Commenters map[string]int `json:"commenters"`
+}
+
+// userMachine is a machine the user logged on to,
+// with the number of alerts of the user on it.
+type userMachine struct {
+	mdatp.Machine
+	AlertCount int `json:"alertCount"`
+}
+
+type configUserShow struct {
+	Since string
+}
+
+func setupCmdUserShow(cmd *cobra.Command, c *configUserShow) *cobra.Command {
+	envconfig.Process("", c)
+	cmd.Flags().SortFlags = false
+	cmd.Flags().StringVarP(&c.Since, "since", "s", c.Since, "Only include the alerts created since this date. Default is to include all alerts.")
+	return cmd
+}
+
+func newCommandUserShow() *cobra.Command {
+	var cmdConfig configUserShow
+	cmd := &cobra.Command{
+		Use:   "show <account>",
+		Short: "Print the alerts and machines related to a user account.",
+		Long: `Print the alerts and machines related to a user account.
+
+The account is identified by its name, without its domain. A single JSON
+document is printed, with the alerts of the user, most recent first, the
+machines the user logged on to, with the number of alerts on each, and
+who the alerts are assigned to and commented by.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var since time.Time
+			if cmdConfig.Since != "" {
+				var err error
+				if since, err = parseDate(cmdConfig.Since); err != nil {
+					return err
+				}
+			}
+			client, err := newClient(userConfig.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			ctx := context.Background()
+			_, alerts, err := client.User.ListAlerts(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			_, machines, err := client.User.ListMachines(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			return writeJSON(newUserReport(args[0], filterAlertsSince(alerts, since), machines))
+		},
+	}
+	return setupCmdUserShow(cmd, &cmdConfig)
+}
+
+// filterAlertsSince returns the alerts created since the provided time,
+// sorted from the most recent. Alerts with an invalid creation time
+// are kept, at the end.
+func filterAlertsSince(alerts []mdatp.Alert, since time.Time) []mdatp.Alert {
+	type datedAlert struct {
+		alert   mdatp.Alert
+		created time.Time
+	}
+	dated := make([]datedAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		created, err := time.Parse(time.RFC3339Nano, mdatp.StringValue(alert.AlertCreationTime))
+		if err == nil && created.Before(since) {
+			continue
+		}
+		dated = append(dated, datedAlert{alert: alert, created: created})
+	}
+	sort.SliceStable(dated, func(i, j int) bool {
+		return dated[i].created.After(dated[j].created)
+	})
+	filtered := make([]mdatp.Alert, len(dated))
+	for i, d := range dated {
+		filtered[i] = d.alert
+	}
+	return filtered
+}
+
+func newUserReport(account string, alerts []mdatp.Alert, machines []mdatp.Machine) *userReport {
+	report := &userReport{
+		Account:    account,
+		Alerts:     alerts,
+		Machines:   make([]userMachine, 0, len(machines)),
+		AssignedTo: make(map[string]int),
+		Commenters: make(map[string]int),
+	}
+	alertCounts := make(map[string]int)
+	for _, alert := range alerts {
+		alertCounts[mdatp.StringValue(alert.MachineID)]++
+		report.AssignedTo[mdatp.StringValue(alert.AssignedTo)]++
+		for _, comment := range alert.Comments {
+			report.Commenters[mdatp.StringValue(comment.CreatedBy)]++
+		}
+	}
+	for _, machine := range machines {
+		report.Machines = append(report.Machines, userMachine{
+			Machine:    machine,
+			AlertCount: alertCounts[mdatp.StringValue(machine.ID)],
+		})
+	}
+	return report
+}
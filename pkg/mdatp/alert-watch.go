@@ -43,11 +43,27 @@ var (
 )
 
 var (
-	// alertChSize defines the channel buffer size
-	// when sending alerts to the encoder goroutine.
-	alertChSize = 1024
+	// recordChSize defines the channel buffer size
+	// when sending records to the encoder goroutine.
+	recordChSize = 1024
 )
 
+// validateTickerInterval returns the interval to use for a ticker
+// triggering queries to the API, defaulting to defaultTickerInterval.
+// An error is returned if the interval could exceed the Microsoft quota.
+func validateTickerInterval(tickerInterval time.Duration) (time.Duration, error) {
+	if tickerInterval == 0 {
+		tickerInterval = defaultTickerInterval
+	}
+	if tickerInterval < minTickerInterval {
+		return 0, fmt.Errorf("tickerInterval is below the minimum allowed(%v): %v", minTickerInterval.String(), tickerInterval.String())
+	}
+	if tickerInterval > maxTickerInterval {
+		return 0, fmt.Errorf("tickerInterval is above the maxmimum allowed(%v): %v", maxTickerInterval.String(), tickerInterval.String())
+	}
+	return tickerInterval, nil
+}
+
 // AlertWatchRequest defines attributes required by the Watch method.
 type AlertWatchRequest struct {
 	OutputSource   io.ReadWriteCloser
@@ -59,6 +75,10 @@ type AlertWatchRequest struct {
 
 	QueryInterval    int
 	QueryMaxInterval int
+
+	// HuntingJobs are run at their own interval, alongside alert
+	// queries, and their rows written to OutputSource as HuntingRecord.
+	HuntingJobs []HuntingJob
 }
 
 // Watch retrieves alerts at regular intervals and writes
@@ -67,19 +87,17 @@ type AlertWatchRequest struct {
 // Two goroutines are started, one to query and one to encode results.
 // The query goroutine, for each tick, and if not already running,
 // a query to the Alert endpoint is made. Alerts retrieved are sent
-// to an alert channel to be encoded by the encoding goroutine.
+// to a record channel to be encoded by the encoding goroutine.
+//
+// A goroutine is also started for each hunting job, sending the rows
+// it retrieves to the same record channel. See HuntingJob. The state
+// must then implement HuntingWatchState.
 //
 // An error is returned if request attribute validation fails.
 func (s *AlertService) Watch(ctx context.Context, req *AlertWatchRequest) error {
-	tickerInterval := time.Duration(req.QueryInterval) * time.Second
-	if tickerInterval == 0 {
-		tickerInterval = defaultTickerInterval
-	}
-	if tickerInterval < minTickerInterval {
-		return fmt.Errorf("tickerInterval is below the minimum allowed(%v): %v", minTickerInterval.String(), tickerInterval.String())
-	}
-	if tickerInterval > maxTickerInterval {
-		return fmt.Errorf("tickerInterval is above the maxmimum allowed(%v): %v", maxTickerInterval.String(), tickerInterval.String())
+	tickerInterval, err := validateTickerInterval(time.Duration(req.QueryInterval) * time.Second)
+	if err != nil {
+		return err
 	}
 
 	maxInterval := time.Duration(req.QueryMaxInterval) * time.Minute
@@ -93,6 +111,17 @@ func (s *AlertService) Watch(ctx context.Context, req *AlertWatchRequest) error
 		return fmt.Errorf("maxInterval is above the maxmimum allowed(%v): %v", maxAlertInterval.String(), tickerInterval.String())
 	}
 
+	if err := validateHuntingJobs(req.HuntingJobs); err != nil {
+		return err
+	}
+	var huntingState HuntingWatchState
+	if len(req.HuntingJobs) > 0 {
+		var ok bool
+		if huntingState, ok = req.State.(HuntingWatchState); !ok {
+			return fmt.Errorf("state must implement HuntingWatchState to run hunting jobs: %T", req.State)
+		}
+	}
+
 	if req.HasStateSource {
 		s.client.logger.Info("using state source")
 		defer req.State.Save(req.StateSourceMaker)
@@ -112,10 +141,22 @@ func (s *AlertService) Watch(ctx context.Context, req *AlertWatchRequest) error
 	}
 
 	encodeDoneCh := make(chan struct{})
-	alertCh := make(chan Alert, alertChSize)
+	recordCh := make(chan interface{}, recordChSize)
 
 	var wg sync.WaitGroup
 
+	// producers tracks the goroutines sending to recordCh,
+	// which is closed once they are all done.
+	var producers sync.WaitGroup
+	startProducer := func(f func()) {
+		producers.Add(1)
+		go func() {
+			defer producers.Done()
+			f()
+		}()
+	}
+	cancelCtx, cancel := context.WithCancel(ctx)
+
 	queryFunc := func(ctx context.Context, lock *uint64, triggered time.Time) {
 		defer atomic.StoreUint64(lock, 0)
 
@@ -159,44 +200,51 @@ func (s *AlertService) Watch(ctx context.Context, req *AlertWatchRequest) error
 			oDataIntervalQuery := makeIntervalOdataQuery("alertCreationTime", start, end)
 			s.client.logger.Debugf("ODATA filter query: %v", oDataIntervalQuery)
 
-			resp, alert, err := s.client.Alert.List(ctx, oDataIntervalQuery)
+			var count int
+			_, err := s.client.Alert.ListPages(ctx, oDataIntervalQuery, func(page *AlertListResponse) error {
+				for _, a := range page.Value {
+					select {
+					case recordCh <- a:
+						count++
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+				return nil
+			})
 			if err != nil {
 				if !errors.Is(err, context.Canceled) {
 					s.client.logger.Errorf("request error: %v", err)
 				}
 				return
 			}
-			if resp.APIError != nil {
-				s.client.logger.Errorf("api error: %+v", resp.APIError)
-				return
-			}
-			s.client.logger.Debugf("query succesfull. Retrieved %d alerts.", len(alert.Value))
-			for _, a := range alert.Value {
-				select {
-				case alertCh <- a:
-				case <-ctx.Done():
-					return
-				}
-			}
+			s.client.logger.Debugf("query succesfull. Retrieved %d alerts.", count)
 			s.client.logger.Debug("saving lastFetchTime")
 			req.State.SetLastFetchTime(end)
 		}
 	}
 
+	for _, job := range req.HuntingJobs {
+		job := job
+		startProducer(func() {
+			s.client.Hunting.watchJob(cancelCtx, job, huntingState, recordCh)
+		})
+	}
+
 	wg.Add(1)
 	go func() {
 		ticker := time.NewTicker(tickerInterval)
-		cancelCtx, cancel := context.WithCancel(ctx)
 
 		defer func() {
 			ticker.Stop()
 			cancel()
-			close(alertCh)
+			producers.Wait()
+			close(recordCh)
 			wg.Done()
 		}()
 
 		var lock uint64
-		go queryFunc(cancelCtx, &lock, time.Now())
+		startProducer(func() { queryFunc(cancelCtx, &lock, time.Now()) })
 
 		for {
 			select {
@@ -210,7 +258,7 @@ func (s *AlertService) Watch(ctx context.Context, req *AlertWatchRequest) error
 					s.client.logger.Debug("busy..")
 					continue
 				}
-				go queryFunc(cancelCtx, &lock, now)
+				startProducer(func() { queryFunc(cancelCtx, &lock, now) })
 			}
 		}
 	}()
@@ -222,8 +270,8 @@ func (s *AlertService) Watch(ctx context.Context, req *AlertWatchRequest) error
 			wg.Done()
 		}()
 
-		for alert := range alertCh {
-			if err := encoder.Encode(&alert); err != nil {
+		for record := range recordCh {
+			if err := encoder.Encode(record); err != nil {
 				s.client.logger.Error(err)
 				return
 			}
@@ -2,32 +2,210 @@ package mdatp
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 )
 
+// Values accepted by the Status attribute of an alert.
+const (
+	AlertStatusNew        = "New"
+	AlertStatusInProgress = "InProgress"
+	AlertStatusResolved   = "Resolved"
+)
+
+// Values accepted by the Classification attribute of an alert.
+const (
+	AlertClassificationUnknown       = "Unknown"
+	AlertClassificationFalsePositive = "FalsePositive"
+	AlertClassificationTruePositive  = "TruePositive"
+)
+
+// Values accepted by the Determination attribute of an alert.
+const (
+	AlertDeterminationNotAvailable      = "NotAvailable"
+	AlertDeterminationApt               = "Apt"
+	AlertDeterminationMalware           = "Malware"
+	AlertDeterminationSecurityPersonnel = "SecurityPersonnel"
+	AlertDeterminationSecurityTesting   = "SecurityTesting"
+	AlertDeterminationUnwantedSoftware  = "UnwantedSoftware"
+	AlertDeterminationOther             = "Other"
+)
+
 // AlertService .
 type AlertService service
 
-// List retrieves alerts using conditions.
+// List retrieves a single page of alerts using conditions.
+// The ODataNextLink attribute of the returned AlertListResponse
+// is set when more alerts are available.
 func (s *AlertService) List(ctx context.Context, odataQueryFilter string) (*Response, *AlertListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var alert *AlertListResponse
+	resp, err := s.client.do(ctx, req, &alert)
+	return resp, alert, err
+}
+
+// ListPages retrieves alerts using conditions, following
+// the @odata.nextLink of each page until all alerts are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *AlertService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*AlertListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &AlertListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*AlertListResponse))
+	})
+}
+
+// ListAll retrieves all alerts using conditions, across all pages.
+func (s *AlertService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Alert, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listAlerts(ctx, req)
+}
+
+// ListFiles retrieves the files related to an alert.
+func (s *AlertService) ListFiles(ctx context.Context, id string) (*Response, []File, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("alerts/%s/files", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []File
+	newPage := func() pager { return &fileListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		files = append(files, p.(*fileListResponse).Value...)
+		return nil
+	})
+	return resp, files, err
+}
+
+// ListIPs retrieves the IPs related to an alert.
+func (s *AlertService) ListIPs(ctx context.Context, id string) (*Response, []IP, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("alerts/%s/ips", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var ips []IP
+	newPage := func() pager { return &ipListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		ips = append(ips, p.(*ipListResponse).Value...)
+		return nil
+	})
+	return resp, ips, err
+}
+
+// ListDomains retrieves the domains related to an alert.
+func (s *AlertService) ListDomains(ctx context.Context, id string) (*Response, []Domain, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("alerts/%s/domains", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var domains []Domain
+	newPage := func() pager { return &domainListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		domains = append(domains, p.(*domainListResponse).Value...)
+		return nil
+	})
+	return resp, domains, err
+}
+
+// GetUser retrieves the user related to an alert.
+func (s *AlertService) GetUser(ctx context.Context, id string) (*Response, *User, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("alerts/%s/user", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var user *User
+	resp, err := s.client.do(ctx, req, &user)
+	return resp, user, err
+}
+
+// GetMachine retrieves the machine related to an alert.
+func (s *AlertService) GetMachine(ctx context.Context, id string) (*Response, *Machine, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("alerts/%s/machine", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machine *Machine
+	resp, err := s.client.do(ctx, req, &machine)
+	return resp, machine, err
+}
+
+func (s *AlertService) newListRequest(odataQueryFilter string) (*http.Request, error) {
 	queryParams := url.Values{}
 	if odataQueryFilter != "" {
 		queryParams.Set("$filter", odataQueryFilter)
 	}
-	req, err := s.client.newRequest("GET", "alerts", queryParams, nil)
+	return s.client.newRequest("GET", "alerts", queryParams, nil)
+}
+
+// Get retrieves an alert by its ID.
+func (s *AlertService) Get(ctx context.Context, id string) (*Response, *Alert, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("alerts/%s", id), nil, nil)
 	if err != nil {
 		return nil, nil, err
 	}
-	var alert *AlertListResponse
+	var alert *Alert
 	resp, err := s.client.do(ctx, req, &alert)
 	return resp, alert, err
 }
 
+// Update updates the attributes of an alert and
+// returns the updated alert.
+func (s *AlertService) Update(ctx context.Context, id string, update *AlertUpdate) (*Response, *Alert, error) {
+	if update == nil {
+		return nil, nil, errors.New("update must be non-nil")
+	}
+	req, err := s.client.newJSONRequest("PATCH", fmt.Sprintf("alerts/%s", id), nil, update)
+	if err != nil {
+		return nil, nil, err
+	}
+	var alert *Alert
+	resp, err := s.client.do(ctx, req, &alert)
+	return resp, alert, err
+}
+
+// BatchUpdate applies the same update to multiple alerts at once.
+func (s *AlertService) BatchUpdate(ctx context.Context, ids []string, update *AlertUpdate) (*Response, error) {
+	if len(ids) == 0 {
+		return nil, errors.New("at least one alert ID is required")
+	}
+	if update == nil {
+		return nil, errors.New("update must be non-nil")
+	}
+	payload := &AlertBatchUpdate{AlertIDs: ids, AlertUpdate: *update}
+	req, err := s.client.newJSONRequest("POST", "alerts/batchUpdate", nil, payload)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.do(ctx, req, nil)
+}
+
+// listAlerts performs req and returns the alerts of every page.
+func (c *Client) listAlerts(ctx context.Context, req *http.Request) (*Response, []Alert, error) {
+	var alerts []Alert
+	newPage := func() pager { return &AlertListResponse{} }
+	resp, err := c.doPages(ctx, req, newPage, func(p pager) error {
+		alerts = append(alerts, p.(*AlertListResponse).Value...)
+		return nil
+	})
+	return resp, alerts, err
+}
+
 // AlertListResponse represents a JSON Object returned by
 // the List Alerts endpoint.
 type AlertListResponse struct {
-	ODataContext string `json:"@odata.context"`
-	Value        []Alert
+	ODataPage
+	Value []Alert
 }
 
 // Alert represents a Microsoft Defender ATP Alert type.
@@ -61,3 +239,20 @@ type AlertComment struct {
 	CreatedBy   *string `json:"createdBy"`
 	CreatedTime *string `json:"createdTime"`
 }
+
+// AlertUpdate holds the alert attributes that can be updated.
+// Nil attributes are left unchanged.
+type AlertUpdate struct {
+	Status         *string `json:"status,omitempty"`
+	AssignedTo     *string `json:"assignedTo,omitempty"`
+	Classification *string `json:"classification,omitempty"`
+	Determination  *string `json:"determination,omitempty"`
+	Comment        *string `json:"comment,omitempty"`
+}
+
+// AlertBatchUpdate represents a JSON Object sent to
+// the Batch Update Alerts endpoint.
+type AlertBatchUpdate struct {
+	AlertIDs []string `json:"alertIds"`
+	AlertUpdate
+}
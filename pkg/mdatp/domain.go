@@ -0,0 +1,62 @@
+package mdatp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// DomainService .
+type DomainService service
+
+// Stats retrieves the prevalence of a domain in the organization over
+// the last lookBackHours hours. A zero lookBackHours uses the API
+// default of 30 days.
+func (s *DomainService) Stats(ctx context.Context, domain string, lookBackHours int) (*Response, *DomainStats, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("domains/%s/stats", domain), statsQueryParams(lookBackHours), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var stats *DomainStats
+	resp, err := s.client.do(ctx, req, &stats)
+	return resp, stats, err
+}
+
+// ListAlerts retrieves the alerts related to a domain.
+func (s *DomainService) ListAlerts(ctx context.Context, domain string) (*Response, []Alert, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("domains/%s/alerts", domain), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listAlerts(ctx, req)
+}
+
+// ListMachines retrieves the machines that communicated with a domain.
+func (s *DomainService) ListMachines(ctx context.Context, domain string) (*Response, []Machine, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("domains/%s/machines", domain), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachines(ctx, req)
+}
+
+// Domain represents a Microsoft Defender ATP Domain entity.
+type Domain struct {
+	Host *string `json:"host"`
+}
+
+// domainListResponse represents a JSON Object
+// returned by endpoints listing domains.
+type domainListResponse struct {
+	ODataPage
+	Value []Domain
+}
+
+// DomainStats represents the statistics of a domain in the organization.
+// The prevalence is returned either as a number or as a string by the API.
+type DomainStats struct {
+	Host          *string      `json:"host"`
+	OrgPrevalence *json.Number `json:"orgPrevalence"`
+	OrgFirstSeen  *string      `json:"orgFirstSeen"`
+	OrgLastSeen   *string      `json:"orgLastSeen"`
+}
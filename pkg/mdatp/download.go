@@ -0,0 +1,176 @@
+package mdatp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// DownloadOptions defines optional attributes used when downloading content.
+type DownloadOptions struct {
+	// ExpectedSHA256 is the hex encoded SHA-256 digest
+	// the content must match, if not empty.
+	ExpectedSHA256 string
+}
+
+// DownloadResult describes downloaded content.
+type DownloadResult struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// errContentRange is wrapped by the errors returned when a partial
+// response does not resume the content where it was interrupted.
+// Resuming again would not fix it, so it is not retried.
+var errContentRange = errors.New("invalid Content-Range")
+
+// downloadStatusError is returned when the download URI
+// responds with a status code other than 200 or 206.
+type downloadStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *downloadStatusError) Error() string {
+	return fmt.Sprintf("download failed: %s", e.Status)
+}
+
+// uriResolver returns a short-lived URI to download content from.
+type uriResolver func(ctx context.Context) (string, error)
+
+// download streams the content available at the URI returned by resolve to w.
+// A transfer that is interrupted is resumed, using a Range request, according
+// to the client RetryPolicy. A new URI is resolved for every attempt since
+// they expire quickly. The size of the content is verified against the
+// length announced by the server, and its SHA-256 digest against the
+// expected one, if provided. Client errors returned by the download URI,
+// other than 408 and 429, are not retried, except for a single retry
+// with a fresh URI on 403.
+func (c *Client) download(ctx context.Context, resolve uriResolver, w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+	state := &downloadState{dst: w, hash: sha256.New(), size: -1}
+	var reresolved bool
+	for attempt := 1; ; attempt++ {
+		err := c.downloadOnce(ctx, resolve, state)
+		if err == nil {
+			break
+		}
+		// errors from the API have already been retried by do.
+		var errResp *ErrorResponse
+		if errors.As(err, &errResp) {
+			return nil, err
+		}
+		if errors.Is(err, errContentRange) {
+			return nil, err
+		}
+		var statusErr *downloadStatusError
+		if errors.As(err, &statusErr) {
+			switch code := statusErr.StatusCode; {
+			case code == http.StatusForbidden && !reresolved:
+				// the URI may have expired, try once more with a fresh one.
+				reresolved = true
+				c.logger.Warnf("download forbidden, resolving a new URI: %v", err)
+				continue
+			case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests, code >= 500:
+			default:
+				return nil, err
+			}
+		}
+		wait, retry := c.retryPolicy.retryDelay(ctx, attempt, nil, err)
+		if !retry {
+			return nil, err
+		}
+		c.logger.Warnf("download interrupted after %d bytes, resuming in %v (attempt %d): %v", state.written, wait, attempt, err)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &DownloadResult{
+		Size:   state.written,
+		SHA256: hex.EncodeToString(state.hash.Sum(nil)),
+	}
+	if opts.ExpectedSHA256 != "" && !strings.EqualFold(opts.ExpectedSHA256, result.SHA256) {
+		return result, fmt.Errorf("sha256 mismatch. got: %s want: %s", result.SHA256, opts.ExpectedSHA256)
+	}
+	return result, nil
+}
+
+// downloadState holds the progress of a download across attempts.
+type downloadState struct {
+	dst     io.Writer
+	hash    hash.Hash
+	written int64
+	// size is the total size announced by the server, or -1 if unknown.
+	size int64
+}
+
+// downloadOnce requests the content starting at the number of bytes
+// already written and copies it to the destination.
+func (c *Client) downloadOnce(ctx context.Context, resolve uriResolver, state *downloadState) error {
+	uri, err := resolve(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("GET", uri, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("User-Agent", c.userAgent)
+	if state.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", state.written))
+	}
+
+	resp, err := c.downloadHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// skip is the number of bytes already written
+	// that the server is sending again.
+	var skip int64
+	switch resp.StatusCode {
+	case http.StatusOK:
+		skip = state.written
+		if resp.ContentLength >= 0 {
+			state.size = resp.ContentLength
+		}
+	case http.StatusPartialContent:
+		var start, end, size int64
+		if _, err := fmt.Sscanf(resp.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &size); err != nil {
+			return fmt.Errorf("%w: %v", errContentRange, err)
+		}
+		if start != state.written {
+			return fmt.Errorf("%w: start mismatch. got: %d want: %d", errContentRange, start, state.written)
+		}
+		state.size = size
+	default:
+		return &downloadStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	if skip > 0 {
+		if _, err := io.CopyN(ioutil.Discard, resp.Body, skip); err != nil {
+			return err
+		}
+	}
+	n, err := io.Copy(io.MultiWriter(state.dst, state.hash), resp.Body)
+	state.written += n
+	if err != nil {
+		return err
+	}
+	if state.size >= 0 && state.written != state.size {
+		return fmt.Errorf("size mismatch. got: %d want: %d: %w", state.written, state.size, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
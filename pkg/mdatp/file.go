@@ -0,0 +1,105 @@
+package mdatp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// FileService .
+type FileService service
+
+// Get retrieves a file by its SHA1 or SHA256 hash.
+func (s *FileService) Get(ctx context.Context, hash string) (*Response, *File, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("files/%s", hash), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var file *File
+	resp, err := s.client.do(ctx, req, &file)
+	return resp, file, err
+}
+
+// Stats retrieves the prevalence of a file in the organization over
+// the last lookBackHours hours. A zero lookBackHours uses the API
+// default of 30 days.
+func (s *FileService) Stats(ctx context.Context, hash string, lookBackHours int) (*Response, *FileStats, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("files/%s/stats", hash), statsQueryParams(lookBackHours), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var stats *FileStats
+	resp, err := s.client.do(ctx, req, &stats)
+	return resp, stats, err
+}
+
+// ListAlerts retrieves the alerts related to a file.
+func (s *FileService) ListAlerts(ctx context.Context, hash string) (*Response, []Alert, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("files/%s/alerts", hash), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listAlerts(ctx, req)
+}
+
+// ListMachines retrieves the machines related to a file.
+func (s *FileService) ListMachines(ctx context.Context, hash string) (*Response, []Machine, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("files/%s/machines", hash), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachines(ctx, req)
+}
+
+// statsQueryParams returns the query parameters of the
+// statistics endpoints, which default to 30 days.
+func statsQueryParams(lookBackHours int) url.Values {
+	queryParams := url.Values{}
+	if lookBackHours > 0 {
+		queryParams.Set("lookBackHours", strconv.Itoa(lookBackHours))
+	}
+	return queryParams
+}
+
+// File represents a Microsoft Defender ATP File entity.
+type File struct {
+	Sha1                *string `json:"sha1"`
+	Sha256              *string `json:"sha256"`
+	Md5                 *string `json:"md5"`
+	GlobalPrevalence    *int64  `json:"globalPrevalence"`
+	GlobalFirstObserved *string `json:"globalFirstObserved"`
+	GlobalLastObserved  *string `json:"globalLastObserved"`
+	Size                *int64  `json:"size"`
+	FileType            *string `json:"fileType"`
+	IsPeFile            *bool   `json:"isPeFile"`
+	FilePublisher       *string `json:"filePublisher"`
+	FileProductName     *string `json:"fileProductName"`
+	Signer              *string `json:"signer"`
+	Issuer              *string `json:"issuer"`
+	SignerHash          *string `json:"signerHash"`
+	IsValidCertificate  *bool   `json:"isValidCertificate"`
+	DeterminationType   *string `json:"determinationType"`
+	DeterminationValue  *string `json:"determinationValue"`
+}
+
+// fileListResponse represents a JSON Object
+// returned by endpoints listing files.
+type fileListResponse struct {
+	ODataPage
+	Value []File
+}
+
+// FileStats represents the statistics of a file in the organization.
+// Prevalences are returned either as numbers or as strings by the API.
+type FileStats struct {
+	Sha1                *string      `json:"sha1"`
+	OrgPrevalence       *json.Number `json:"orgPrevalence"`
+	OrgFirstSeen        *string      `json:"orgFirstSeen"`
+	OrgLastSeen         *string      `json:"orgLastSeen"`
+	GlobalPrevalence    *json.Number `json:"globalPrevalence"`
+	GlobalFirstObserved *string      `json:"globalFirstObserved"`
+	GlobalLastObserved  *string      `json:"globalLastObserved"`
+	TopFileNames        []string     `json:"topFileNames"`
+}
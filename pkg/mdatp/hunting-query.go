@@ -0,0 +1,190 @@
+package mdatp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// savedQueryExt is the extension of saved query files.
+	savedQueryExt = ".kql"
+	// frontMatterDelimiter delimits the YAML front-matter of saved query files.
+	frontMatterDelimiter = "---"
+)
+
+// SavedQuery is a named Advanced Hunting query template.
+//
+// Saved queries are stored in .kql files starting with an optional YAML
+// front-matter, delimited by --- lines, that declares the attributes of
+// the query. The rest of the file is the query, as a Go text/template
+// referencing parameters using {{ .Name }}. The quote template
+// function returns its argument as a KQL string literal.
+type SavedQuery struct {
+	Name        string           `yaml:"name" json:"name"`
+	Description string           `yaml:"description" json:"description,omitempty"`
+	Parameters  []QueryParameter `yaml:"parameters" json:"parameters,omitempty"`
+	Query       string           `yaml:"-" json:"query,omitempty"`
+
+	template *template.Template
+}
+
+// QueryParameter declares a parameter of a SavedQuery.
+type QueryParameter struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Required    bool   `yaml:"required" json:"required,omitempty"`
+	Default     string `yaml:"default" json:"default,omitempty"`
+}
+
+// LoadSavedQueries parses every .kql file of dir, sorted by name.
+// The name of a query defaults to the name of its file, without extension.
+func LoadSavedQueries(dir string) ([]SavedQuery, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+savedQueryExt))
+	if err != nil {
+		return nil, err
+	}
+	var queries []SavedQuery
+	names := make(map[string]string)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		query, err := ParseSavedQuery(strings.TrimSuffix(filepath.Base(path), savedQueryExt), f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		if other, ok := names[query.Name]; ok {
+			return nil, fmt.Errorf("%s: query %s already defined in %s", path, query.Name, other)
+		}
+		names[query.Name] = path
+		queries = append(queries, *query)
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries, nil
+}
+
+// FindSavedQuery returns the saved query of dir having the provided name.
+func FindSavedQuery(dir, name string) (*SavedQuery, error) {
+	queries, err := LoadSavedQueries(dir)
+	if err != nil {
+		return nil, err
+	}
+	for i := range queries {
+		if queries[i].Name == name {
+			return &queries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("saved query not found in %s: %s", dir, name)
+}
+
+// ParseSavedQuery parses a saved query. name is used if
+// the front-matter does not define one.
+func ParseSavedQuery(name string, r io.Reader) (*SavedQuery, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	frontMatter, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &SavedQuery{Name: name}
+	if err := yaml.UnmarshalStrict(frontMatter, query); err != nil {
+		return nil, fmt.Errorf("invalid front-matter: %v", err)
+	}
+	query.Query = strings.TrimSpace(string(body))
+	if query.Query == "" {
+		return nil, fmt.Errorf("query is empty")
+	}
+	seen := make(map[string]bool)
+	for _, p := range query.Parameters {
+		if p.Name == "" {
+			return nil, fmt.Errorf("parameter name is required")
+		}
+		if seen[p.Name] {
+			return nil, fmt.Errorf("duplicate parameter: %s", p.Name)
+		}
+		seen[p.Name] = true
+	}
+
+	query.template, err = template.New(query.Name).
+		Funcs(template.FuncMap{"quote": kqlQuote}).
+		Option("missingkey=error").
+		Parse(query.Query)
+	if err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// Render returns the query with its parameters replaced by the provided
+// values, or their default. An error is returned if a required parameter
+// has no value, or if a value is provided for an undeclared parameter.
+func (q *SavedQuery) Render(params map[string]string) (string, error) {
+	values := make(map[string]string, len(q.Parameters))
+	declared := make(map[string]bool, len(q.Parameters))
+	for _, p := range q.Parameters {
+		declared[p.Name] = true
+		value, ok := params[p.Name]
+		if !ok || value == "" {
+			value = p.Default
+		}
+		if value == "" && p.Required {
+			return "", fmt.Errorf("missing required parameter: %s", p.Name)
+		}
+		values[p.Name] = value
+	}
+	for name := range params {
+		if !declared[name] {
+			return "", fmt.Errorf("unknown parameter: %s", name)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := q.template.Execute(&buf, values); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// splitFrontMatter splits data into its YAML front-matter,
+// if any, and the remaining body.
+func splitFrontMatter(data []byte) ([]byte, []byte, error) {
+	text := strings.Replace(string(data), "\r\n", "\n", -1)
+	lines := strings.SplitAfter(text, "\n")
+	if strings.TrimSpace(lines[0]) != frontMatterDelimiter {
+		return nil, []byte(text), nil
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == frontMatterDelimiter {
+			return []byte(strings.Join(lines[1:i], "")), []byte(strings.Join(lines[i+1:], "")), nil
+		}
+	}
+	return nil, nil, fmt.Errorf("front-matter is not terminated by %s", frontMatterDelimiter)
+}
+
+// kqlStringReplacer escapes the characters of a KQL string literal.
+var kqlStringReplacer = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// kqlQuote returns s as a double quoted KQL string literal.
+func kqlQuote(s string) string {
+	return `"` + kqlStringReplacer.Replace(s) + `"`
+}
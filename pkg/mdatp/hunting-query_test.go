@@ -0,0 +1,44 @@
+package mdatp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSavedQueryRender(t *testing.T) {
+	data := `---
+description: Logons on a device.
+parameters:
+  - name: DeviceName
+    required: true
+  - name: Lookback
+    default: 7d
+---
+DeviceLogonEvents
+| where Timestamp > ago({{ .Lookback }})
+| where DeviceName == {{ quote .DeviceName }}
+`
+	query, err := ParseSavedQuery("device-logons", strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Name != "device-logons" || len(query.Parameters) != 2 {
+		t.Errorf("query attributes mismatch. got: %+v", query)
+	}
+
+	got, err := query.Render(map[string]string{"DeviceName": `pc"1`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "DeviceLogonEvents\n| where Timestamp > ago(7d)\n| where DeviceName == \"pc\\\"1\""
+	if got != want {
+		t.Errorf("rendered query mismatch.\ngot:  %s\nwant: %s", got, want)
+	}
+
+	if _, err := query.Render(nil); err == nil {
+		t.Errorf("expected an error for a missing required parameter")
+	}
+	if _, err := query.Render(map[string]string{"DeviceName": "pc", "Typo": "x"}); err == nil {
+		t.Errorf("expected an error for an unknown parameter")
+	}
+}
@@ -0,0 +1,136 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// minHuntingJobInterval is a lower bound constraint, since
+	// Advanced Hunting queries have a stricter quota than other calls.
+	minHuntingJobInterval = 1 * time.Minute
+	// maxHuntingJobInterval is an upper bound constraint.
+	maxHuntingJobInterval = maxTickerInterval
+
+	// maxHuntingLookBehind is the retention of Advanced Hunting data.
+	maxHuntingLookBehind = thirtyDays
+)
+
+// HuntingJob is an Advanced Hunting query run at regular intervals by Watch.
+//
+// The query is prefixed with the declaration of WindowStart and WindowEnd
+// datetime values, which it should use to only return the rows between the
+// end of the window of the last successful run and the time of the current
+// run, for example:
+//
+//	DeviceProcessEvents
+//	| where Timestamp > WindowStart and Timestamp <= WindowEnd
+//
+// The first run covers a single interval.
+type HuntingJob struct {
+	// Name identifies the job in the watch state and in the records.
+	Name     string
+	Query    string
+	Interval time.Duration
+}
+
+// HuntingRecord is a row returned by a hunting job, as written by Watch.
+type HuntingRecord struct {
+	Job string     `json:"job"`
+	Row HuntingRow `json:"row"`
+}
+
+// validateHuntingJobs returns an error if a job is invalid,
+// or if multiple jobs have the same name.
+func validateHuntingJobs(jobs []HuntingJob) error {
+	names := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		if job.Name == "" {
+			return errors.New("hunting job name is required")
+		}
+		if names[job.Name] {
+			return fmt.Errorf("duplicate hunting job: %s", job.Name)
+		}
+		names[job.Name] = true
+		if strings.TrimSpace(job.Query) == "" {
+			return fmt.Errorf("hunting job %s: query is required", job.Name)
+		}
+		if job.Interval < minHuntingJobInterval {
+			return fmt.Errorf("hunting job %s: interval is below the minimum allowed(%v): %v", job.Name, minHuntingJobInterval, job.Interval)
+		}
+		if job.Interval > maxHuntingJobInterval {
+			return fmt.Errorf("hunting job %s: interval is above the maxmimum allowed(%v): %v", job.Name, maxHuntingJobInterval, job.Interval)
+		}
+	}
+	return nil
+}
+
+// watchJob runs job at every interval until ctx is done, sending the
+// resulting rows to recordCh. Runs are made with a low priority so that
+// they do not starve the other requests of the client.
+func (s *HuntingService) watchJob(ctx context.Context, job HuntingJob, state HuntingWatchState, recordCh chan<- interface{}) {
+	ctx = WithLowPriority(ctx)
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	now := time.Now()
+	for {
+		if err := s.runJob(ctx, job, state, now, recordCh); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				s.client.logger.Errorf("hunting job %s: %v", job.Name, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case now = <-ticker.C:
+		}
+	}
+}
+
+// runJob runs job over the window ending at end and, once all rows
+// are sent to recordCh, saves end as the last run time of the job.
+func (s *HuntingService) runJob(ctx context.Context, job HuntingJob, state HuntingWatchState, end time.Time, recordCh chan<- interface{}) error {
+	start, err := state.GetLastRunTime(job.Name)
+	if err != nil {
+		s.client.logger.Debugf("hunting job %s: could not get last run time from state: %v", job.Name, err)
+	}
+	if start.IsZero() {
+		start = end.Add(-job.Interval)
+	}
+	if end.Sub(start) > maxHuntingLookBehind {
+		start = end.Add(-maxHuntingLookBehind)
+	}
+
+	s.client.logger.Debugf("hunting job %s: running from %v to %v", job.Name, start, end)
+	_, result, err := s.Run(ctx, huntingWindowQuery(job.Query, start, end))
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		// the last run time is not saved, so the window is run again on the next tick.
+		return errors.New("empty hunting query response")
+	}
+	s.client.logger.Debugf("hunting job %s: retrieved %d rows", job.Name, len(result.Results))
+	for _, row := range result.Results {
+		select {
+		case recordCh <- HuntingRecord{Job: job.Name, Row: row}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return state.SetLastRunTime(job.Name, end)
+}
+
+// huntingWindowQuery prefixes query with the declaration
+// of the WindowStart and WindowEnd datetime values.
+func huntingWindowQuery(query string, start, end time.Time) string {
+	return fmt.Sprintf("let WindowStart = datetime(%s);\nlet WindowEnd = datetime(%s);\n%s",
+		start.UTC().Format(time.RFC3339Nano),
+		end.UTC().Format(time.RFC3339Nano),
+		query,
+	)
+}
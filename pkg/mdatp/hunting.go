@@ -0,0 +1,62 @@
+package mdatp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// HuntingService .
+type HuntingService service
+
+// Run runs an Advanced Hunting query, written in KQL.
+func (s *HuntingService) Run(ctx context.Context, query string) (*Response, *HuntingResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, nil, errors.New("query must not be empty")
+	}
+	payload := &huntingRequest{Query: query}
+	req, err := s.client.newJSONRequest("POST", "advancedqueries/run", nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var result *HuntingResult
+	resp, err := s.client.do(ctx, req, &result)
+	return resp, result, err
+}
+
+// huntingRequest represents a JSON Object sent to
+// the Run Advanced Query endpoint.
+type huntingRequest struct {
+	Query string `json:"Query"`
+}
+
+// HuntingResult represents a JSON Object returned by
+// the Run Advanced Query endpoint.
+type HuntingResult struct {
+	Schema  []HuntingColumn `json:"Schema"`
+	Results []HuntingRow    `json:"Results"`
+}
+
+// HuntingColumn describes a column of the results of a query.
+type HuntingColumn struct {
+	Name string `json:"Name"`
+	Type string `json:"Type"`
+}
+
+// HuntingRow is a row of the results of a query, indexed by column name.
+// Numbers are decoded as json.Number to preserve long values.
+type HuntingRow map[string]interface{}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *HuntingRow) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil {
+		return err
+	}
+	*r = row
+	return nil
+}
@@ -0,0 +1,171 @@
+package mdatp
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ReadIndicatorsJSON decodes a JSON array of indicators.
+func ReadIndicatorsJSON(r io.Reader) ([]Indicator, error) {
+	var indicators []Indicator
+	if err := json.NewDecoder(r).Decode(&indicators); err != nil {
+		return nil, err
+	}
+	return indicators, nil
+}
+
+// ReadIndicatorsCSV decodes indicators from CSV records. The first
+// record is a header naming, for each column, the JSON attribute of
+// Indicator it holds, such as indicatorValue or expirationTime.
+// Multiple rbacGroupNames are separated using semicolons.
+func ReadIndicatorsCSV(r io.Reader) ([]Indicator, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("could not read header: %v", err)
+	}
+
+	var indicators []Indicator
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return indicators, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var indicator Indicator
+		for i, column := range header {
+			if err := setIndicatorAttribute(&indicator, column, record[i]); err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		indicators = append(indicators, indicator)
+	}
+}
+
+// setIndicatorAttribute sets the attribute named after its JSON
+// name, case-insensitively, to value. Empty values are ignored.
+func setIndicatorAttribute(i *Indicator, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	switch strings.ToLower(name) {
+	case "indicatorvalue":
+		i.IndicatorValue = String(value)
+	case "indicatortype":
+		i.IndicatorType = IndicatorType(value)
+	case "action":
+		i.Action = IndicatorAction(value)
+	case "application":
+		i.Application = String(value)
+	case "title":
+		i.Title = String(value)
+	case "description":
+		i.Description = String(value)
+	case "recommendedactions":
+		i.RecommendedActions = String(value)
+	case "severity":
+		i.Severity = String(value)
+	case "expirationtime":
+		i.ExpirationTime = String(value)
+	case "generatealert":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid generateAlert value: %v", value)
+		}
+		i.GenerateAlert = Bool(b)
+	case "rbacgroupnames":
+		i.RbacGroupNames = strings.Split(value, ";")
+	default:
+		return fmt.Errorf("unsupported column: %v", name)
+	}
+	return nil
+}
+
+var (
+	// stixPatternRegexp matches STIX patterns made of a single
+	// comparison, such as [file:hashes.'SHA-256' = '...'].
+	stixPatternRegexp = regexp.MustCompile(`^\[\s*([a-z0-9-]+):([A-Za-z0-9_.'-]+)\s*=\s*'([^']*)'\s*\]$`)
+
+	// stixIndicatorTypes maps STIX object paths to indicator types.
+	stixIndicatorTypes = map[string]IndicatorType{
+		"file:hashes.'sha-256'":           IndicatorTypeFileSha256,
+		"file:hashes.sha256":              IndicatorTypeFileSha256,
+		"file:hashes.'sha-1'":             IndicatorTypeFileSha1,
+		"file:hashes.sha1":                IndicatorTypeFileSha1,
+		"file:hashes.'md5'":               IndicatorTypeFileMd5,
+		"file:hashes.md5":                 IndicatorTypeFileMd5,
+		"x509-certificate:hashes.'sha-1'": IndicatorTypeCertificateThumbprint,
+		"x509-certificate:hashes.sha1":    IndicatorTypeCertificateThumbprint,
+		"ipv4-addr:value":                 IndicatorTypeIPAddress,
+		"ipv6-addr:value":                 IndicatorTypeIPAddress,
+		"domain-name:value":               IndicatorTypeDomainName,
+		"url:value":                       IndicatorTypeURL,
+	}
+)
+
+// stixIndicator holds the attributes of a STIX 2 indicator object.
+type stixIndicator struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Pattern     string `json:"pattern"`
+	ValidUntil  string `json:"valid_until"`
+}
+
+// ReadIndicatorsSTIX decodes indicators from STIX 2 objects, one per line.
+// Objects that are not indicators are skipped, and only patterns made of
+// a single comparison on a supported object path are accepted.
+// The name of the STIX indicator is used as title and, if it has none,
+// as description, while valid_until is used as expiration time.
+func ReadIndicatorsSTIX(r io.Reader) ([]Indicator, error) {
+	var indicators []Indicator
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		var obj stixIndicator
+		if err := json.Unmarshal([]byte(data), &obj); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if obj.Type != "indicator" {
+			continue
+		}
+		match := stixPatternRegexp.FindStringSubmatch(obj.Pattern)
+		if match == nil {
+			return nil, fmt.Errorf("line %d: unsupported pattern: %v", line, obj.Pattern)
+		}
+		indicatorType, ok := stixIndicatorTypes[strings.ToLower(match[1]+":"+match[2])]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unsupported object path: %v:%v", line, match[1], match[2])
+		}
+
+		indicator := Indicator{
+			IndicatorValue: String(match[3]),
+			IndicatorType:  indicatorType,
+		}
+		if obj.Name != "" {
+			indicator.Title = String(obj.Name)
+			indicator.Description = String(obj.Name)
+		}
+		if obj.Description != "" {
+			indicator.Description = String(obj.Description)
+		}
+		if obj.ValidUntil != "" {
+			indicator.ExpirationTime = String(obj.ValidUntil)
+		}
+		indicators = append(indicators, indicator)
+	}
+	return indicators, scanner.Err()
+}
@@ -0,0 +1,196 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// IndicatorSyncOp is the operation required to reconcile an indicator.
+type IndicatorSyncOp string
+
+// Values of IndicatorSyncOp.
+const (
+	IndicatorSyncCreate IndicatorSyncOp = "create"
+	IndicatorSyncUpdate IndicatorSyncOp = "update"
+	IndicatorSyncDelete IndicatorSyncOp = "delete"
+)
+
+// IndicatorSyncOptions defines how desired indicators are
+// reconciled with the indicators of the tenant.
+type IndicatorSyncOptions struct {
+	// Application identifies the indicators owned by the caller.
+	// It is set on created indicators that do not define one.
+	Application string
+	// DeleteStale deletes the indicators owned by Application
+	// that are not desired anymore. Application is required.
+	DeleteStale bool
+}
+
+// IndicatorSyncChange is a change required to reconcile the tenant
+// with the desired indicators. Indicator is the desired indicator
+// for creations and updates, and the existing one for deletions.
+type IndicatorSyncChange struct {
+	Op        IndicatorSyncOp `json:"op"`
+	Indicator Indicator       `json:"indicator"`
+	// Changed lists the attributes that differ, for updates.
+	Changed []string `json:"changed,omitempty"`
+}
+
+// IndicatorSyncPlan holds the changes required to reconcile the tenant
+// with the desired indicators, in the order they are applied.
+type IndicatorSyncPlan struct {
+	Changes []IndicatorSyncChange
+}
+
+// PlanSync retrieves the indicators of the tenant and returns the changes
+// required for them to match the desired ones. Indicators are matched
+// by type and case-insensitive value, and only their action and
+// expiration time are compared. A desired indicator without expiration
+// time keeps the expiration time of the existing one. Desired indicators
+// only get opts.Application when they are created.
+func (s *IndicatorService) PlanSync(ctx context.Context, desired []Indicator, opts *IndicatorSyncOptions) (*IndicatorSyncPlan, error) {
+	if opts == nil {
+		opts = &IndicatorSyncOptions{}
+	}
+	if opts.DeleteStale && opts.Application == "" {
+		return nil, errors.New("an application is required to delete stale indicators")
+	}
+	_, current, err := s.ListAll(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return planIndicatorSync(desired, current, opts)
+}
+
+// ApplySync applies the changes of plan. Creations and updates are
+// imported in batches, and their results returned, then stale
+// indicators are deleted one by one.
+func (s *IndicatorService) ApplySync(ctx context.Context, plan *IndicatorSyncPlan) ([]IndicatorImportResult, error) {
+	var upserts []Indicator
+	var deletes []string
+	for _, change := range plan.Changes {
+		switch change.Op {
+		case IndicatorSyncCreate, IndicatorSyncUpdate:
+			upserts = append(upserts, change.Indicator)
+		case IndicatorSyncDelete:
+			deletes = append(deletes, StringValue(change.Indicator.ID))
+		}
+	}
+
+	var results []IndicatorImportResult
+	if len(upserts) > 0 {
+		var err error
+		if _, results, err = s.Import(ctx, upserts); err != nil {
+			return results, err
+		}
+	}
+	for _, id := range deletes {
+		if _, err := s.Delete(ctx, id); err != nil {
+			return results, fmt.Errorf("could not delete indicator %s: %w", id, err)
+		}
+	}
+	return results, nil
+}
+
+// planIndicatorSync returns the changes required for current to match desired.
+func planIndicatorSync(desired, current []Indicator, opts *IndicatorSyncOptions) (*IndicatorSyncPlan, error) {
+	existing := make(map[string]Indicator, len(current))
+	for _, indicator := range current {
+		existing[indicatorKey(indicator)] = indicator
+	}
+
+	plan := &IndicatorSyncPlan{}
+	seen := make(map[string]bool, len(desired))
+	for _, indicator := range desired {
+		key := indicatorKey(indicator)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate indicator: %s %s", indicator.IndicatorType, StringValue(indicator.IndicatorValue))
+		}
+		seen[key] = true
+
+		current, ok := existing[key]
+		if !ok {
+			if indicator.Application == nil && opts.Application != "" {
+				indicator.Application = String(opts.Application)
+			}
+			plan.Changes = append(plan.Changes, IndicatorSyncChange{Op: IndicatorSyncCreate, Indicator: indicator})
+			continue
+		}
+		var changed []string
+		if indicator.Action != current.Action {
+			changed = append(changed, "action")
+		}
+		// an indicator without expiration time does not
+		// clear the expiration time of the existing one.
+		if indicator.ExpirationTime != nil && !sameTime(indicator.ExpirationTime, current.ExpirationTime) {
+			changed = append(changed, "expirationTime")
+		}
+		if len(changed) > 0 {
+			plan.Changes = append(plan.Changes, IndicatorSyncChange{Op: IndicatorSyncUpdate, Indicator: mergeIndicator(indicator, current), Changed: changed})
+		}
+	}
+
+	if opts.DeleteStale {
+		for _, indicator := range current {
+			if StringValue(indicator.Application) != opts.Application || seen[indicatorKey(indicator)] {
+				continue
+			}
+			plan.Changes = append(plan.Changes, IndicatorSyncChange{Op: IndicatorSyncDelete, Indicator: indicator})
+		}
+	}
+	return plan, nil
+}
+
+// mergeIndicator returns desired with the attributes it leaves unset
+// copied from current. The import API replaces the whole indicator,
+// so an update must carry the attributes it does not change, such as
+// the RBAC groups it is scoped to, and the application owning it.
+func mergeIndicator(desired, current Indicator) Indicator {
+	if desired.Application == nil {
+		desired.Application = current.Application
+	}
+	if desired.Title == nil {
+		desired.Title = current.Title
+	}
+	if desired.Description == nil {
+		desired.Description = current.Description
+	}
+	if desired.RecommendedActions == nil {
+		desired.RecommendedActions = current.RecommendedActions
+	}
+	if desired.Severity == nil {
+		desired.Severity = current.Severity
+	}
+	if desired.GenerateAlert == nil {
+		desired.GenerateAlert = current.GenerateAlert
+	}
+	if desired.RbacGroupNames == nil {
+		desired.RbacGroupNames = current.RbacGroupNames
+	}
+	if desired.ExpirationTime == nil {
+		desired.ExpirationTime = current.ExpirationTime
+	}
+	return desired
+}
+
+// indicatorKey identifies an indicator by its type and value.
+func indicatorKey(i Indicator) string {
+	return fmt.Sprintf("%s:%s", i.IndicatorType, strings.ToLower(StringValue(i.IndicatorValue)))
+}
+
+// sameTime reports whether a and b represent the same instant,
+// falling back to comparing them as strings if they cannot be parsed.
+func sameTime(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	ta, errA := time.Parse(time.RFC3339, *a)
+	tb, errB := time.Parse(time.RFC3339, *b)
+	if errA != nil || errB != nil {
+		return *a == *b
+	}
+	return ta.Equal(tb)
+}
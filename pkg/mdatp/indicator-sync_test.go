@@ -0,0 +1,146 @@
+package mdatp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlanIndicatorSync(t *testing.T) {
+	desired := []Indicator{
+		{IndicatorValue: String("ABC"), IndicatorType: IndicatorTypeFileSha1, Action: IndicatorActionBlock},
+		{IndicatorValue: String("1.2.3.4"), IndicatorType: IndicatorTypeIPAddress, Action: IndicatorActionAlert, ExpirationTime: String("2020-06-01T00:00:00Z")},
+		{IndicatorValue: String("evil.com"), IndicatorType: IndicatorTypeDomainName, Action: IndicatorActionAlert},
+	}
+	current := []Indicator{
+		{ID: String("1"), IndicatorValue: String("abc"), IndicatorType: IndicatorTypeFileSha1, Action: IndicatorActionAlert, Application: String("feed")},
+		{ID: String("2"), IndicatorValue: String("1.2.3.4"), IndicatorType: IndicatorTypeIPAddress, Action: IndicatorActionAlert, ExpirationTime: String("2020-06-01T00:00:00.000Z"), Application: String("feed")},
+		{ID: String("3"), IndicatorValue: String("stale.com"), IndicatorType: IndicatorTypeDomainName, Action: IndicatorActionAlert, Application: String("feed")},
+		{ID: String("4"), IndicatorValue: String("other.com"), IndicatorType: IndicatorTypeDomainName, Action: IndicatorActionAlert, Application: String("someone else")},
+	}
+
+	plan, err := planIndicatorSync(desired, current, &IndicatorSyncOptions{Application: "feed", DeleteStale: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	type summary struct {
+		op      IndicatorSyncOp
+		value   string
+		changed []string
+	}
+	var got []summary
+	for _, c := range plan.Changes {
+		got = append(got, summary{c.Op, *c.Indicator.IndicatorValue, c.Changed})
+	}
+	want := []summary{
+		{IndicatorSyncUpdate, "ABC", []string{"action"}},
+		{IndicatorSyncCreate, "evil.com", nil},
+		{IndicatorSyncDelete, "stale.com", nil},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("plan mismatch.\ngot:  %+v\nwant: %+v", got, want)
+	}
+	if app := plan.Changes[1].Indicator.Application; app == nil || *app != "feed" {
+		t.Errorf("application not set on created indicator. got: %v", app)
+	}
+
+	if _, err := planIndicatorSync(append(desired, desired[0]), nil, &IndicatorSyncOptions{}); err == nil {
+		t.Errorf("expected an error for duplicate indicators")
+	}
+}
+
+func TestPlanIndicatorSyncUpdateKeepsCurrentAttributes(t *testing.T) {
+	current := []Indicator{
+		{ID: String("1"), IndicatorValue: String("abc"), IndicatorType: IndicatorTypeFileSha1, Action: IndicatorActionAlert, Title: String("bad file"), Description: String("seen in the wild"), ExpirationTime: String("2020-06-01T00:00:00Z")},
+	}
+
+	// the expiration time is not cleared when the desired indicator has none.
+	desired := []Indicator{
+		{IndicatorValue: String("abc"), IndicatorType: IndicatorTypeFileSha1, Action: IndicatorActionAlert},
+	}
+	plan, err := planIndicatorSync(desired, current, &IndicatorSyncOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Changes) != 0 {
+		t.Errorf("expected no change. got: %+v", plan.Changes)
+	}
+
+	// updates keep the title, description and expiration time of the existing indicator.
+	desired[0].Action = IndicatorActionBlock
+	plan, err = planIndicatorSync(desired, current, &IndicatorSyncOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Changes) != 1 {
+		t.Fatalf("change count mismatch. got: %v want: %v", len(plan.Changes), 1)
+	}
+	got := plan.Changes[0]
+	if !reflect.DeepEqual(got.Changed, []string{"action"}) {
+		t.Errorf("changed mismatch. got: %v want: %v", got.Changed, []string{"action"})
+	}
+	if StringValue(got.Indicator.Title) != "bad file" || StringValue(got.Indicator.Description) != "seen in the wild" {
+		t.Errorf("title and description not copied. got: %+v", got.Indicator)
+	}
+	if StringValue(got.Indicator.ExpirationTime) != "2020-06-01T00:00:00Z" {
+		t.Errorf("expiration time mismatch. got: %v want: %v", StringValue(got.Indicator.ExpirationTime), "2020-06-01T00:00:00Z")
+	}
+}
+
+func TestPlanIndicatorSyncUpdateKeepsScope(t *testing.T) {
+	current := []Indicator{
+		{ID: String("1"), IndicatorValue: String("abc"), IndicatorType: IndicatorTypeFileSha1, Action: IndicatorActionAlert, Application: String("portal"), Severity: String("High"), RbacGroupNames: []string{"servers"}},
+	}
+	desired := []Indicator{
+		{IndicatorValue: String("abc"), IndicatorType: IndicatorTypeFileSha1, Action: IndicatorActionBlock},
+	}
+
+	plan, err := planIndicatorSync(desired, current, &IndicatorSyncOptions{Application: "feed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(plan.Changes) != 1 {
+		t.Fatalf("change count mismatch. got: %v want: %v", len(plan.Changes), 1)
+	}
+	got := plan.Changes[0].Indicator
+	if !reflect.DeepEqual(got.RbacGroupNames, []string{"servers"}) {
+		t.Errorf("rbac group names mismatch. got: %v want: %v", got.RbacGroupNames, []string{"servers"})
+	}
+	if StringValue(got.Severity) != "High" {
+		t.Errorf("severity mismatch. got: %v want: %v", StringValue(got.Severity), "High")
+	}
+	if StringValue(got.Application) != "portal" {
+		t.Errorf("application mismatch. got: %v want: %v", StringValue(got.Application), "portal")
+	}
+}
+
+func TestReadIndicators(t *testing.T) {
+	csvData := "indicatorValue,indicatorType,action,title,description\n" +
+		"abc,FileSha1,Block,bad file,seen in the wild\n"
+	indicators, err := ReadIndicatorsCSV(strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("unexpected CSV error: %v", err)
+	}
+	if len(indicators) != 1 || *indicators[0].IndicatorValue != "abc" || indicators[0].Action != IndicatorActionBlock {
+		t.Errorf("CSV indicators mismatch. got: %+v", indicators)
+	}
+
+	stixData := `{"type":"indicator","name":"bad file","pattern":"[file:hashes.'SHA-256' = 'def']","valid_until":"2020-06-01T00:00:00Z"}
+{"type":"malware","name":"ignored"}
+{"type":"indicator","name":"bad ip","pattern":"[ipv4-addr:value = '1.2.3.4']"}
+`
+	indicators, err = ReadIndicatorsSTIX(strings.NewReader(stixData))
+	if err != nil {
+		t.Fatalf("unexpected STIX error: %v", err)
+	}
+	if len(indicators) != 2 {
+		t.Fatalf("STIX indicator count mismatch. got: %v want: %v", len(indicators), 2)
+	}
+	if indicators[0].IndicatorType != IndicatorTypeFileSha256 || *indicators[0].ExpirationTime != "2020-06-01T00:00:00Z" {
+		t.Errorf("STIX file indicator mismatch. got: %+v", indicators[0])
+	}
+	if indicators[1].IndicatorType != IndicatorTypeIPAddress || *indicators[1].Title != "bad ip" {
+		t.Errorf("STIX ip indicator mismatch. got: %+v", indicators[1])
+	}
+}
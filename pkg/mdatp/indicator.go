@@ -0,0 +1,194 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+var (
+	// maxIndicatorsPerImport is the maximum number of
+	// indicators accepted by a single import request.
+	maxIndicatorsPerImport = 500
+)
+
+// IndicatorType is the type of value an indicator matches.
+type IndicatorType string
+
+// Values accepted as IndicatorType.
+const (
+	IndicatorTypeFileSha1              IndicatorType = "FileSha1"
+	IndicatorTypeFileSha256            IndicatorType = "FileSha256"
+	IndicatorTypeFileMd5               IndicatorType = "FileMd5"
+	IndicatorTypeCertificateThumbprint IndicatorType = "CertificateThumbprint"
+	IndicatorTypeIPAddress             IndicatorType = "IpAddress"
+	IndicatorTypeDomainName            IndicatorType = "DomainName"
+	IndicatorTypeURL                   IndicatorType = "Url"
+)
+
+// IndicatorAction is the action taken when an indicator is matched.
+type IndicatorAction string
+
+// Values accepted as IndicatorAction.
+const (
+	IndicatorActionAlert         IndicatorAction = "Alert"
+	IndicatorActionAlertAndBlock IndicatorAction = "AlertAndBlock"
+	IndicatorActionAllowed       IndicatorAction = "Allowed"
+	IndicatorActionBlock         IndicatorAction = "Block"
+	IndicatorActionAudit         IndicatorAction = "Audit"
+	IndicatorActionWarn          IndicatorAction = "Warn"
+)
+
+// IndicatorService .
+type IndicatorService service
+
+// List retrieves a single page of indicators using conditions.
+// The ODataNextLink attribute of the returned IndicatorListResponse
+// is set when more indicators are available.
+func (s *IndicatorService) List(ctx context.Context, odataQueryFilter string) (*Response, *IndicatorListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var indicators *IndicatorListResponse
+	resp, err := s.client.do(ctx, req, &indicators)
+	return resp, indicators, err
+}
+
+// ListPages retrieves indicators using conditions, following
+// the @odata.nextLink of each page until all indicators are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *IndicatorService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*IndicatorListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &IndicatorListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*IndicatorListResponse))
+	})
+}
+
+// ListAll retrieves all indicators using conditions, across all pages.
+func (s *IndicatorService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Indicator, error) {
+	var indicators []Indicator
+	resp, err := s.ListPages(ctx, odataQueryFilter, func(page *IndicatorListResponse) error {
+		indicators = append(indicators, page.Value...)
+		return nil
+	})
+	return resp, indicators, err
+}
+
+func (s *IndicatorService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "indicators", queryParams, nil)
+}
+
+// Submit creates an indicator, or updates the existing
+// indicator having the same value and type.
+func (s *IndicatorService) Submit(ctx context.Context, indicator *Indicator) (*Response, *Indicator, error) {
+	if indicator == nil {
+		return nil, nil, errors.New("indicator must be non-nil")
+	}
+	req, err := s.client.newJSONRequest("POST", "indicators", nil, indicator)
+	if err != nil {
+		return nil, nil, err
+	}
+	var submitted *Indicator
+	resp, err := s.client.do(ctx, req, &submitted)
+	return resp, submitted, err
+}
+
+// Delete deletes an indicator by its ID.
+func (s *IndicatorService) Delete(ctx context.Context, id string) (*Response, error) {
+	req, err := s.client.newRequest("DELETE", fmt.Sprintf("indicators/%s", id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.do(ctx, req, nil)
+}
+
+// Import creates or updates indicators in batches. Indicators are split
+// into as many requests as needed to respect the API per call limit.
+// Failures are reported per indicator in the returned results.
+// It returns the response of the last request made.
+func (s *IndicatorService) Import(ctx context.Context, indicators []Indicator) (*Response, []IndicatorImportResult, error) {
+	var resp *Response
+	var results []IndicatorImportResult
+	for start := 0; start < len(indicators); start += maxIndicatorsPerImport {
+		end := start + maxIndicatorsPerImport
+		if end > len(indicators) {
+			end = len(indicators)
+		}
+		payload := &indicatorImportRequest{Indicators: indicators[start:end]}
+		req, err := s.client.newJSONRequest("POST", "indicators/import", nil, payload)
+		if err != nil {
+			return resp, results, err
+		}
+		var page *indicatorImportResponse
+		resp, err = s.client.do(ctx, req, &page)
+		if err != nil {
+			return resp, results, err
+		}
+		if page == nil {
+			return resp, results, errors.New("empty indicator import response")
+		}
+		results = append(results, page.Value...)
+	}
+	return resp, results, nil
+}
+
+// IndicatorListResponse represents a JSON Object returned by
+// the List Indicators endpoint.
+type IndicatorListResponse struct {
+	ODataPage
+	Value []Indicator
+}
+
+// Indicator represents a Microsoft Defender ATP Indicator type.
+type Indicator struct {
+	ID                      *string         `json:"id,omitempty"`
+	IndicatorValue          *string         `json:"indicatorValue,omitempty"`
+	IndicatorType           IndicatorType   `json:"indicatorType,omitempty"`
+	Action                  IndicatorAction `json:"action,omitempty"`
+	Application             *string         `json:"application,omitempty"`
+	Source                  *string         `json:"source,omitempty"`
+	SourceType              *string         `json:"sourceType,omitempty"`
+	Title                   *string         `json:"title,omitempty"`
+	Description             *string         `json:"description,omitempty"`
+	RecommendedActions      *string         `json:"recommendedActions,omitempty"`
+	Severity                *string         `json:"severity,omitempty"`
+	GenerateAlert           *bool           `json:"generateAlert,omitempty"`
+	RbacGroupNames          []string        `json:"rbacGroupNames,omitempty"`
+	CreationTimeDateTimeUtc *string         `json:"creationTimeDateTimeUtc,omitempty"`
+	CreatedBy               *string         `json:"createdBy,omitempty"`
+	ExpirationTime          *string         `json:"expirationTime,omitempty"`
+	LastUpdateTime          *string         `json:"lastUpdateTime,omitempty"`
+	LastUpdatedBy           *string         `json:"lastUpdatedBy,omitempty"`
+}
+
+// indicatorImportRequest represents a JSON Object sent to
+// the Import Indicators endpoint.
+type indicatorImportRequest struct {
+	Indicators []Indicator `json:"Indicators"`
+}
+
+// indicatorImportResponse represents a JSON Object returned by
+// the Import Indicators endpoint.
+type indicatorImportResponse struct {
+	Value []IndicatorImportResult
+}
+
+// IndicatorImportResult is the outcome of importing a single indicator.
+type IndicatorImportResult struct {
+	ID            *string `json:"id"`
+	Indicator     *string `json:"indicator"`
+	IsFailed      *bool   `json:"isFailed"`
+	FailureReason *string `json:"failureReason"`
+}
@@ -0,0 +1,140 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// InvestigationState is the state of an automated investigation.
+type InvestigationState string
+
+// Values of InvestigationState.
+const (
+	InvestigationStateUnknown                InvestigationState = "Unknown"
+	InvestigationStateTerminated             InvestigationState = "Terminated"
+	InvestigationStateSuccessfullyRemediated InvestigationState = "SuccessfullyRemediated"
+	InvestigationStateBenign                 InvestigationState = "Benign"
+	InvestigationStateFailed                 InvestigationState = "Failed"
+	InvestigationStatePartiallyRemediated    InvestigationState = "PartiallyRemediated"
+	InvestigationStateRunning                InvestigationState = "Running"
+	InvestigationStatePendingApproval        InvestigationState = "PendingApproval"
+	InvestigationStatePendingResource        InvestigationState = "PendingResource"
+	InvestigationStatePartiallyInvestigated  InvestigationState = "PartiallyInvestigated"
+	InvestigationStateTerminatedByUser       InvestigationState = "TerminatedByUser"
+	InvestigationStateTerminatedBySystem     InvestigationState = "TerminatedBySystem"
+	InvestigationStateQueued                 InvestigationState = "Queued"
+	InvestigationStateInnerFailure           InvestigationState = "InnerFailure"
+	InvestigationStatePreexistingAlert       InvestigationState = "PreexistingAlert"
+	InvestigationStateUnsupportedOs          InvestigationState = "UnsupportedOs"
+	InvestigationStateUnsupportedAlertType   InvestigationState = "UnsupportedAlertType"
+	InvestigationStateSuppressedAlert        InvestigationState = "SuppressedAlert"
+)
+
+// IsDone reports whether the state is terminal, that is
+// whether the investigation is neither running nor pending.
+func (s InvestigationState) IsDone() bool {
+	switch s {
+	case InvestigationStateRunning, InvestigationStatePendingApproval, InvestigationStatePendingResource, InvestigationStateQueued:
+		return false
+	}
+	return true
+}
+
+// InvestigationService .
+type InvestigationService service
+
+// List retrieves a single page of investigations using conditions.
+// The ODataNextLink attribute of the returned InvestigationListResponse
+// is set when more investigations are available.
+func (s *InvestigationService) List(ctx context.Context, odataQueryFilter string) (*Response, *InvestigationListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var investigations *InvestigationListResponse
+	resp, err := s.client.do(ctx, req, &investigations)
+	return resp, investigations, err
+}
+
+// ListPages retrieves investigations using conditions, following
+// the @odata.nextLink of each page until all investigations are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *InvestigationService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*InvestigationListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &InvestigationListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*InvestigationListResponse))
+	})
+}
+
+// ListAll retrieves all investigations using conditions, across all pages.
+func (s *InvestigationService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Investigation, error) {
+	var investigations []Investigation
+	resp, err := s.ListPages(ctx, odataQueryFilter, func(page *InvestigationListResponse) error {
+		investigations = append(investigations, page.Value...)
+		return nil
+	})
+	return resp, investigations, err
+}
+
+func (s *InvestigationService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "investigations", queryParams, nil)
+}
+
+// Get retrieves an investigation by its ID.
+func (s *InvestigationService) Get(ctx context.Context, id string) (*Response, *Investigation, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("investigations/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var investigation *Investigation
+	resp, err := s.client.do(ctx, req, &investigation)
+	return resp, investigation, err
+}
+
+// Start starts an automated investigation on a machine.
+// As for machine actions, the API requires a comment.
+func (s *InvestigationService) Start(ctx context.Context, machineID, comment string) (*Response, *Investigation, error) {
+	if comment == "" {
+		return nil, nil, errors.New("comment is required")
+	}
+	payload := &machineActionRequest{Comment: comment}
+	req, err := s.client.newJSONRequest("POST", fmt.Sprintf("machines/%s/startInvestigation", machineID), nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var investigation *Investigation
+	resp, err := s.client.do(ctx, req, &investigation)
+	return resp, investigation, err
+}
+
+// Investigation represents a Microsoft Defender ATP Investigation type.
+type Investigation struct {
+	ID                 *string             `json:"id"`
+	StartTime          *string             `json:"startTime"`
+	EndTime            *string             `json:"endTime"`
+	CancelledBy        *string             `json:"cancelledBy"`
+	InvestigationState *InvestigationState `json:"investigationState"`
+	StatusDetails      *string             `json:"statusDetails"`
+	MachineID          *string             `json:"machineId"`
+	ComputerDNSName    *string             `json:"computerDnsName"`
+	TriggeringAlertID  *string             `json:"triggeringAlertId"`
+}
+
+// InvestigationListResponse represents a JSON Object returned by
+// the List Investigations endpoint.
+type InvestigationListResponse struct {
+	ODataPage
+	Value []Investigation
+}
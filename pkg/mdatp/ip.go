@@ -0,0 +1,53 @@
+package mdatp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// IPService .
+type IPService service
+
+// Stats retrieves the prevalence of an IP in the organization over
+// the last lookBackHours hours. A zero lookBackHours uses the API
+// default of 30 days.
+func (s *IPService) Stats(ctx context.Context, ip string, lookBackHours int) (*Response, *IPStats, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("ips/%s/stats", ip), statsQueryParams(lookBackHours), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var stats *IPStats
+	resp, err := s.client.do(ctx, req, &stats)
+	return resp, stats, err
+}
+
+// ListAlerts retrieves the alerts related to an IP.
+func (s *IPService) ListAlerts(ctx context.Context, ip string) (*Response, []Alert, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("ips/%s/alerts", ip), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listAlerts(ctx, req)
+}
+
+// IP represents a Microsoft Defender ATP IP entity.
+type IP struct {
+	ID *string `json:"id"`
+}
+
+// ipListResponse represents a JSON Object
+// returned by endpoints listing IPs.
+type ipListResponse struct {
+	ODataPage
+	Value []IP
+}
+
+// IPStats represents the statistics of an IP in the organization.
+// The prevalence is returned either as a number or as a string by the API.
+type IPStats struct {
+	IPAddress     *string      `json:"ipAddress"`
+	OrgPrevalence *json.Number `json:"orgPrevalence"`
+	OrgFirstSeen  *string      `json:"orgFirstSeen"`
+	OrgLastSeen   *string      `json:"orgLastSeen"`
+}
@@ -0,0 +1,238 @@
+package mdatp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+)
+
+// LiveResponseCommandType is the type of a live response command.
+type LiveResponseCommandType string
+
+// Values accepted as LiveResponseCommandType.
+const (
+	LiveResponseCommandRunScript LiveResponseCommandType = "RunScript"
+	LiveResponseCommandGetFile   LiveResponseCommandType = "GetFile"
+	LiveResponseCommandPutFile   LiveResponseCommandType = "PutFile"
+)
+
+// LiveResponseCommand is a command run on a machine by a live response
+// machine action. Use RunScript, GetFile and PutFile to build them.
+type LiveResponseCommand struct {
+	Type   LiveResponseCommandType `json:"type"`
+	Params []LiveResponseParam     `json:"params"`
+}
+
+// LiveResponseParam is a parameter of a live response command.
+type LiveResponseParam struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// RunScript returns a command running a script of the live response
+// library, with the provided arguments, if any.
+func RunScript(scriptName, args string) LiveResponseCommand {
+	params := []LiveResponseParam{{Key: "ScriptName", Value: scriptName}}
+	if args != "" {
+		params = append(params, LiveResponseParam{Key: "Args", Value: args})
+	}
+	return LiveResponseCommand{Type: LiveResponseCommandRunScript, Params: params}
+}
+
+// GetFile returns a command collecting the file at path on the machine.
+func GetFile(path string) LiveResponseCommand {
+	return LiveResponseCommand{
+		Type:   LiveResponseCommandGetFile,
+		Params: []LiveResponseParam{{Key: "Path", Value: path}},
+	}
+}
+
+// PutFile returns a command copying a file of the live
+// response library to the machine.
+func PutFile(fileName string) LiveResponseCommand {
+	return LiveResponseCommand{
+		Type:   LiveResponseCommandPutFile,
+		Params: []LiveResponseParam{{Key: "FileName", Value: fileName}},
+	}
+}
+
+// LiveResponseService .
+type LiveResponseService service
+
+// Run runs commands on a machine, in order, as a single machine action.
+// Its progress can be followed using MachineActionService.WaitForCompletion,
+// and the result of each command retrieved using DownloadCommandResult.
+func (s *LiveResponseService) Run(ctx context.Context, machineID, comment string, commands ...LiveResponseCommand) (*Response, *MachineAction, error) {
+	if comment == "" {
+		return nil, nil, errors.New("comment is required")
+	}
+	if len(commands) == 0 {
+		return nil, nil, errors.New("at least one command is required")
+	}
+	payload := &liveResponseRequest{Commands: commands, Comment: comment}
+	req, err := s.client.newJSONRequest("POST", fmt.Sprintf("machines/%s/runliveresponse", machineID), nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machineAction *MachineAction
+	resp, err := s.client.do(ctx, req, &machineAction)
+	return resp, machineAction, err
+}
+
+// GetCommandResultURI retrieves a short-lived URI to download the result
+// of the command at index of a live response machine action. The result
+// of a GetFile command is the collected file, and the result of a
+// RunScript command is a JSON document holding the script output.
+func (s *LiveResponseService) GetCommandResultURI(ctx context.Context, actionID string, index int) (*Response, string, error) {
+	path := fmt.Sprintf("machineactions/%s/GetLiveResponseResultDownloadLink(index=%d)", actionID, index)
+	req, err := s.client.newRequest("GET", path, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	var uri *uriResponse
+	resp, err := s.client.do(ctx, req, &uri)
+	if err != nil {
+		return resp, "", err
+	}
+	if uri == nil || uri.Value == "" {
+		return resp, "", fmt.Errorf("machine action %s: no result URI returned for command %d", actionID, index)
+	}
+	return resp, uri.Value, nil
+}
+
+// DownloadCommandResult streams the result of the command at index
+// of a live response machine action to w.
+// See DownloadOptions for the verifications available.
+func (s *LiveResponseService) DownloadCommandResult(ctx context.Context, actionID string, index int, w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	resolve := func(ctx context.Context) (string, error) {
+		_, uri, err := s.GetCommandResultURI(ctx, actionID, index)
+		return uri, err
+	}
+	return s.client.download(ctx, resolve, w, opts)
+}
+
+// ListLibraryFiles retrieves the files of the live response library.
+func (s *LiveResponseService) ListLibraryFiles(ctx context.Context) (*Response, []LibraryFile, error) {
+	req, err := s.client.newRequest("GET", "libraryfiles", nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []LibraryFile
+	newPage := func() pager { return &libraryFileListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		files = append(files, p.(*libraryFileListResponse).Value...)
+		return nil
+	})
+	return resp, files, err
+}
+
+// LibraryFileUploadOptions defines optional attributes
+// of a file uploaded to the live response library.
+type LibraryFileUploadOptions struct {
+	Description           string
+	HasParameters         bool
+	ParametersDescription string
+	// OverrideIfExists replaces a library file of the same name.
+	OverrideIfExists bool
+}
+
+// UploadLibraryFile uploads the content of r to the live response
+// library as fileName. The content is buffered in memory, so that
+// the request can be retried.
+func (s *LiveResponseService) UploadLibraryFile(ctx context.Context, fileName string, r io.Reader, opts *LibraryFileUploadOptions) (*Response, *LibraryFile, error) {
+	if fileName == "" {
+		return nil, nil, errors.New("file name must not be empty")
+	}
+	if opts == nil {
+		opts = &LibraryFileUploadOptions{}
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := io.Copy(fw, r); err != nil {
+		return nil, nil, err
+	}
+	fields := []struct{ name, value string }{
+		{"Description", opts.Description},
+		{"HasParameters", strconv.FormatBool(opts.HasParameters)},
+		{"ParametersDescription", opts.ParametersDescription},
+		{"OverrideIfExists", strconv.FormatBool(opts.OverrideIfExists)},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if err := mw.WriteField(f.name, f.value); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	req, err := s.client.newRequest("POST", "libraryfiles", nil, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	var file *LibraryFile
+	resp, err := s.client.do(ctx, req, &file)
+	return resp, file, err
+}
+
+// DeleteLibraryFile deletes a file from the live response library.
+func (s *LiveResponseService) DeleteLibraryFile(ctx context.Context, fileName string) (*Response, error) {
+	if fileName == "" {
+		return nil, errors.New("file name must not be empty")
+	}
+	req, err := s.client.newRequest("DELETE", fmt.Sprintf("libraryfiles/%s", fileName), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.do(ctx, req, nil)
+}
+
+// liveResponseRequest represents a JSON Object sent to
+// the Run Live Response endpoint.
+type liveResponseRequest struct {
+	Commands []LiveResponseCommand `json:"Commands"`
+	Comment  string                `json:"Comment"`
+}
+
+// LiveResponseCommandResult is the state of a command
+// run by a live response machine action.
+type LiveResponseCommandResult struct {
+	Index         *int                 `json:"index"`
+	StartTime     *string              `json:"startTime"`
+	EndTime       *string              `json:"endTime"`
+	CommandStatus *string              `json:"commandStatus"`
+	Errors        []string             `json:"errors"`
+	Command       *LiveResponseCommand `json:"command"`
+}
+
+// LibraryFile represents a file of the live response library.
+type LibraryFile struct {
+	FileName              *string `json:"fileName"`
+	Sha256                *string `json:"sha256"`
+	Description           *string `json:"description"`
+	CreationTime          *string `json:"creationTime"`
+	LastUpdatedTime       *string `json:"lastUpdatedTime"`
+	CreatedBy             *string `json:"createdBy"`
+	HasParameters         *bool   `json:"hasParameters"`
+	ParametersDescription *string `json:"parametersDescription"`
+}
+
+// libraryFileListResponse represents a JSON Object
+// returned by the List Library Files endpoint.
+type libraryFileListResponse struct {
+	ODataPage
+	Value []LibraryFile
+}
@@ -0,0 +1,305 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// IsolationType defines how a machine is isolated.
+type IsolationType string
+
+// Values accepted as IsolationType.
+const (
+	IsolationTypeFull      IsolationType = "Full"
+	IsolationTypeSelective IsolationType = "Selective"
+)
+
+// ScanType defines the type of antivirus scan to run.
+type ScanType string
+
+// Values accepted as ScanType.
+const (
+	ScanTypeQuick ScanType = "Quick"
+	ScanTypeFull  ScanType = "Full"
+)
+
+// Values of the Status attribute of a machine action.
+// Succeeded, Failed, TimeOut and Cancelled are terminal.
+const (
+	MachineActionStatusPending    = "Pending"
+	MachineActionStatusInProgress = "InProgress"
+	MachineActionStatusSucceeded  = "Succeeded"
+	MachineActionStatusFailed     = "Failed"
+	MachineActionStatusTimeOut    = "TimeOut"
+	MachineActionStatusCancelled  = "Cancelled"
+)
+
+// MachineActionService .
+type MachineActionService service
+
+// List retrieves a single page of machine actions using conditions.
+// The ODataNextLink attribute of the returned MachineActionListResponse
+// is set when more machine actions are available.
+func (s *MachineActionService) List(ctx context.Context, odataQueryFilter string) (*Response, *MachineActionListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machineActions *MachineActionListResponse
+	resp, err := s.client.do(ctx, req, &machineActions)
+	return resp, machineActions, err
+}
+
+// ListPages retrieves machine actions using conditions, following
+// the @odata.nextLink of each page until all machine actions are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *MachineActionService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*MachineActionListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &MachineActionListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*MachineActionListResponse))
+	})
+}
+
+// ListAll retrieves all machine actions using conditions, across all pages.
+func (s *MachineActionService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []MachineAction, error) {
+	var machineActions []MachineAction
+	resp, err := s.ListPages(ctx, odataQueryFilter, func(page *MachineActionListResponse) error {
+		machineActions = append(machineActions, page.Value...)
+		return nil
+	})
+	return resp, machineActions, err
+}
+
+func (s *MachineActionService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "machineactions", queryParams, nil)
+}
+
+// Get retrieves a machine action by its ID.
+func (s *MachineActionService) Get(ctx context.Context, id string) (*Response, *MachineAction, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machineactions/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machineAction *MachineAction
+	resp, err := s.client.do(ctx, req, &machineAction)
+	return resp, machineAction, err
+}
+
+// Cancel cancels a machine action that is not yet completed.
+func (s *MachineActionService) Cancel(ctx context.Context, id, comment string) (*Response, *MachineAction, error) {
+	if comment == "" {
+		return nil, nil, errors.New("comment is required")
+	}
+	payload := &machineActionRequest{Comment: comment}
+	req, err := s.client.newJSONRequest("POST", fmt.Sprintf("machineactions/%s/cancel", id), nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machineAction *MachineAction
+	resp, err := s.client.do(ctx, req, &machineAction)
+	return resp, machineAction, err
+}
+
+// ValidatePollInterval returns an error if pollInterval cannot be used
+// by WaitForCompletion, so that callers can check it before submitting
+// machine actions.
+func ValidatePollInterval(pollInterval time.Duration) error {
+	_, err := validateTickerInterval(pollInterval)
+	return err
+}
+
+// WaitForCompletion polls a machine action every pollInterval until
+// it reaches a terminal status, and returns it. A zero pollInterval uses
+// the same default as Watch, and the same bounds apply so that polling
+// does not exceed the Microsoft quota.
+// The returned machine action may have a Failed, TimeOut or
+// Cancelled status, it is up to the caller to check it.
+func (s *MachineActionService) WaitForCompletion(ctx context.Context, id string, pollInterval time.Duration) (*Response, *MachineAction, error) {
+	pollInterval, err := validateTickerInterval(pollInterval)
+	if err != nil {
+		return nil, nil, err
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, machineAction, err := s.Get(ctx, id)
+		if err != nil {
+			return resp, machineAction, err
+		}
+		if machineAction == nil {
+			return resp, nil, fmt.Errorf("machine action %s: empty response", id)
+		}
+		if machineAction.IsDone() {
+			return resp, machineAction, nil
+		}
+		s.client.logger.Debugf("machine action %s status: %v", id, StringValue(machineAction.Status))
+		select {
+		case <-ctx.Done():
+			return resp, machineAction, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Isolate isolates a machine from the network. Selective isolation
+// keeps Outlook, Teams and Skype for Business connected.
+func (s *MachineActionService) Isolate(ctx context.Context, machineID, comment string, isolationType IsolationType) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "isolate", &machineActionRequest{Comment: comment, IsolationType: isolationType})
+}
+
+// Unisolate releases a machine from isolation.
+func (s *MachineActionService) Unisolate(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "unisolate", &machineActionRequest{Comment: comment})
+}
+
+// RunAntiVirusScan starts a Windows Defender Antivirus scan on a machine.
+func (s *MachineActionService) RunAntiVirusScan(ctx context.Context, machineID, comment string, scanType ScanType) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "runAntiVirusScan", &machineActionRequest{Comment: comment, ScanType: scanType})
+}
+
+// CollectInvestigationPackage collects an investigation package from a machine.
+func (s *MachineActionService) CollectInvestigationPackage(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "collectInvestigationPackage", &machineActionRequest{Comment: comment})
+}
+
+// RestrictCodeExecution restricts the execution of all applications
+// on a machine, except a predefined set.
+func (s *MachineActionService) RestrictCodeExecution(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "restrictCodeExecution", &machineActionRequest{Comment: comment})
+}
+
+// UnrestrictCodeExecution enables the execution of any application on a machine.
+func (s *MachineActionService) UnrestrictCodeExecution(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "unrestrictCodeExecution", &machineActionRequest{Comment: comment})
+}
+
+// Offboard offboards a machine from Microsoft Defender ATP.
+func (s *MachineActionService) Offboard(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+	return s.post(ctx, machineID, "offboard", &machineActionRequest{Comment: comment})
+}
+
+// StopAndQuarantineFile stops the execution of a file on a machine
+// and deletes it, identified by its SHA1 hash.
+func (s *MachineActionService) StopAndQuarantineFile(ctx context.Context, machineID, sha1, comment string) (*Response, *MachineAction, error) {
+	if sha1 == "" {
+		return nil, nil, errors.New("sha1 is required")
+	}
+	return s.post(ctx, machineID, "StopAndQuarantineFile", &machineActionRequest{Comment: comment, Sha1: sha1})
+}
+
+// post submits an action against a machine. The API requires a comment
+// for every action, so an empty comment is rejected before any request is made.
+func (s *MachineActionService) post(ctx context.Context, machineID, action string, payload *machineActionRequest) (*Response, *MachineAction, error) {
+	if payload.Comment == "" {
+		return nil, nil, errors.New("comment is required")
+	}
+	req, err := s.client.newJSONRequest("POST", fmt.Sprintf("machines/%s/%s", machineID, action), nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machineAction *MachineAction
+	resp, err := s.client.do(ctx, req, &machineAction)
+	return resp, machineAction, err
+}
+
+// machineActionRequest represents a JSON Object sent to
+// the machine action endpoints.
+type machineActionRequest struct {
+	Comment       string        `json:"Comment"`
+	IsolationType IsolationType `json:"IsolationType,omitempty"`
+	ScanType      ScanType      `json:"ScanType,omitempty"`
+	Sha1          string        `json:"Sha1,omitempty"`
+}
+
+// MachineAction represents a Microsoft Defender ATP Machine Action type.
+type MachineAction struct {
+	ID                      *string                `json:"id"`
+	Type                    *string                `json:"type"`
+	Scope                   *string                `json:"scope"`
+	Requestor               *string                `json:"requestor"`
+	RequestorComment        *string                `json:"requestorComment"`
+	Status                  *string                `json:"status"`
+	MachineID               *string                `json:"machineId"`
+	ComputerDNSName         *string                `json:"computerDnsName"`
+	CreationDateTimeUtc     *string                `json:"creationDateTimeUtc"`
+	LastUpdateDateTimeUtc   *string                `json:"lastUpdateDateTimeUtc"`
+	CancellationRequestor   *string                `json:"cancellationRequestor"`
+	CancellationComment     *string                `json:"cancellationComment"`
+	CancellationDateTimeUtc *string                `json:"cancellationDateTimeUtc"`
+	ErrorHResult            *int                   `json:"errorHResult"`
+	RelatedFileInfo         *MachineActionFileInfo `json:"relatedFileInfo"`
+	// Commands is only set for live response machine actions.
+	Commands []LiveResponseCommandResult `json:"commands,omitempty"`
+}
+
+// GetPackageURI retrieves a short-lived URI to download the investigation
+// package collected by a CollectInvestigationPackage machine action.
+func (s *MachineActionService) GetPackageURI(ctx context.Context, id string) (*Response, string, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machineactions/%s/GetPackageUri", id), nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	var uri *uriResponse
+	resp, err := s.client.do(ctx, req, &uri)
+	if err != nil {
+		return resp, "", err
+	}
+	if uri == nil || uri.Value == "" {
+		return resp, "", fmt.Errorf("machine action %s: no package URI returned", id)
+	}
+	return resp, uri.Value, nil
+}
+
+// DownloadPackage streams the investigation package collected by
+// a CollectInvestigationPackage machine action to w.
+// See DownloadOptions for the verifications available.
+func (s *MachineActionService) DownloadPackage(ctx context.Context, id string, w io.Writer, opts *DownloadOptions) (*DownloadResult, error) {
+	resolve := func(ctx context.Context) (string, error) {
+		_, uri, err := s.GetPackageURI(ctx, id)
+		return uri, err
+	}
+	return s.client.download(ctx, resolve, w, opts)
+}
+
+// uriResponse represents a JSON Object returned by
+// the endpoints providing download URIs.
+type uriResponse struct {
+	Value string `json:"value"`
+}
+
+// IsDone reports whether the machine action reached a terminal status.
+func (a *MachineAction) IsDone() bool {
+	switch StringValue(a.Status) {
+	case MachineActionStatusSucceeded, MachineActionStatusFailed, MachineActionStatusTimeOut, MachineActionStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// MachineActionListResponse represents a JSON Object returned by
+// the List Machine Actions endpoint.
+type MachineActionListResponse struct {
+	ODataPage
+	Value []MachineAction
+}
+
+// MachineActionFileInfo is an object contained in MachineAction.
+type MachineActionFileInfo struct {
+	FileIdentifier     *string `json:"fileIdentifier"`
+	FileIdentifierType *string `json:"fileIdentifierType"`
+}
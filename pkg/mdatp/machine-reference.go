@@ -0,0 +1,33 @@
+package mdatp
+
+import (
+	"context"
+	"net/http"
+)
+
+// MachineReference is a minimal representation of a machine,
+// as returned by the Threat & Vulnerability Management endpoints.
+type MachineReference struct {
+	ID              *string `json:"id"`
+	ComputerDNSName *string `json:"computerDnsName"`
+	OSPlatform      *string `json:"osPlatform"`
+	RbacGroupName   *string `json:"rbacGroupName"`
+}
+
+// machineReferenceListResponse represents a JSON Object
+// returned by endpoints listing machine references.
+type machineReferenceListResponse struct {
+	ODataPage
+	Value []MachineReference
+}
+
+// listMachineReferences performs req and returns the machine references of every page.
+func (c *Client) listMachineReferences(ctx context.Context, req *http.Request) (*Response, []MachineReference, error) {
+	var machines []MachineReference
+	newPage := func() pager { return &machineReferenceListResponse{} }
+	resp, err := c.doPages(ctx, req, newPage, func(p pager) error {
+		machines = append(machines, p.(*machineReferenceListResponse).Value...)
+		return nil
+	})
+	return resp, machines, err
+}
@@ -0,0 +1,86 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// maxMachinesPerTagRequest is the maximum number of
+	// machines accepted by a single bulk tag request.
+	maxMachinesPerTagRequest = 500
+)
+
+// MachineTagAction defines whether a tag is added or removed.
+type MachineTagAction string
+
+// Values accepted as MachineTagAction.
+const (
+	MachineTagActionAdd    MachineTagAction = "Add"
+	MachineTagActionRemove MachineTagAction = "Remove"
+)
+
+// AddTag adds a tag to a machine, and returns the updated machine.
+func (s *MachineService) AddTag(ctx context.Context, id, tag string) (*Response, *Machine, error) {
+	return s.tag(ctx, id, tag, MachineTagActionAdd)
+}
+
+// RemoveTag removes a tag from a machine, and returns the updated machine.
+func (s *MachineService) RemoveTag(ctx context.Context, id, tag string) (*Response, *Machine, error) {
+	return s.tag(ctx, id, tag, MachineTagActionRemove)
+}
+
+func (s *MachineService) tag(ctx context.Context, id, tag string, action MachineTagAction) (*Response, *Machine, error) {
+	if tag == "" {
+		return nil, nil, errors.New("tag must not be empty")
+	}
+	payload := &machineTagRequest{Value: tag, Action: action}
+	req, err := s.client.newJSONRequest("POST", fmt.Sprintf("machines/%s/tags", id), nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machine *Machine
+	resp, err := s.client.do(ctx, req, &machine)
+	return resp, machine, err
+}
+
+// AddOrRemoveTagForMultipleMachines adds or removes a tag on many machines
+// at once. Machines are split into as many requests as needed to respect
+// the API per call limit. It returns the response of the last request made
+// and the number of machines, from the start of machineIDs, the requests
+// succeeded for. The other machines are left unchanged when an error occurs.
+func (s *MachineService) AddOrRemoveTagForMultipleMachines(ctx context.Context, machineIDs []string, tag string, action MachineTagAction) (*Response, int, error) {
+	if tag == "" {
+		return nil, 0, errors.New("tag must not be empty")
+	}
+	switch action {
+	case MachineTagActionAdd, MachineTagActionRemove:
+	default:
+		return nil, 0, errors.New("invalid tag action: " + string(action))
+	}
+	var resp *Response
+	for start := 0; start < len(machineIDs); start += maxMachinesPerTagRequest {
+		end := start + maxMachinesPerTagRequest
+		if end > len(machineIDs) {
+			end = len(machineIDs)
+		}
+		payload := &machineTagRequest{Value: tag, Action: action, MachineIDs: machineIDs[start:end]}
+		req, err := s.client.newJSONRequest("POST", "machines/AddOrRemoveTagForMultipleMachines", nil, payload)
+		if err != nil {
+			return resp, start, err
+		}
+		if resp, err = s.client.do(ctx, req, nil); err != nil {
+			return resp, start, err
+		}
+	}
+	return resp, len(machineIDs), nil
+}
+
+// machineTagRequest represents a JSON Object sent to the
+// Add or Remove Machine Tags endpoints.
+type machineTagRequest struct {
+	Value      string           `json:"Value"`
+	Action     MachineTagAction `json:"Action"`
+	MachineIDs []string         `json:"MachineIds,omitempty"`
+}
@@ -0,0 +1,189 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Machine represents a Microsoft Defender ATP Machine entity.
+type Machine struct {
+	ID                    *string  `json:"id"`
+	ComputerDNSName       *string  `json:"computerDnsName"`
+	FirstSeen             *string  `json:"firstSeen"`
+	LastSeen              *string  `json:"lastSeen"`
+	OSPlatform            *string  `json:"osPlatform"`
+	OSVersion             *string  `json:"osVersion"`
+	OSProcessor           *string  `json:"osProcessor"`
+	OSBuild               *int64   `json:"osBuild"`
+	Version               *string  `json:"version"`
+	LastIPAddress         *string  `json:"lastIpAddress"`
+	LastExternalIPAddress *string  `json:"lastExternalIpAddress"`
+	AgentVersion          *string  `json:"agentVersion"`
+	HealthStatus          *string  `json:"healthStatus"`
+	OnboardingStatus      *string  `json:"onboardingStatus"`
+	DeviceValue           *string  `json:"deviceValue"`
+	RbacGroupID           *int     `json:"rbacGroupId"`
+	RbacGroupName         *string  `json:"rbacGroupName"`
+	RiskScore             *string  `json:"riskScore"`
+	ExposureLevel         *string  `json:"exposureLevel"`
+	IsAadJoined           *bool    `json:"isAadJoined"`
+	AadDeviceID           *string  `json:"aadDeviceId"`
+	MachineTags           []string `json:"machineTags"`
+}
+
+// MachineService .
+type MachineService service
+
+// List retrieves a single page of machines using conditions.
+// The ODataNextLink attribute of the returned MachineListResponse
+// is set when more machines are available.
+func (s *MachineService) List(ctx context.Context, odataQueryFilter string) (*Response, *MachineListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machines *MachineListResponse
+	resp, err := s.client.do(ctx, req, &machines)
+	return resp, machines, err
+}
+
+// ListPages retrieves machines using conditions, following
+// the @odata.nextLink of each page until all machines are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *MachineService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*MachineListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &MachineListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*MachineListResponse))
+	})
+}
+
+// ListAll retrieves all machines using conditions, across all pages.
+func (s *MachineService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Machine, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachines(ctx, req)
+}
+
+func (s *MachineService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "machines", queryParams, nil)
+}
+
+// Get retrieves a machine by its ID.
+func (s *MachineService) Get(ctx context.Context, id string) (*Response, *Machine, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machines/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machine *Machine
+	resp, err := s.client.do(ctx, req, &machine)
+	return resp, machine, err
+}
+
+// ListLogonUsers retrieves the users who logged on to a machine.
+func (s *MachineService) ListLogonUsers(ctx context.Context, id string) (*Response, []User, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machines/%s/logonusers", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var users []User
+	newPage := func() pager { return &userListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		users = append(users, p.(*userListResponse).Value...)
+		return nil
+	})
+	return resp, users, err
+}
+
+// ListAlerts retrieves the alerts related to a machine.
+func (s *MachineService) ListAlerts(ctx context.Context, id string) (*Response, []Alert, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machines/%s/alerts", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listAlerts(ctx, req)
+}
+
+// FindByIP retrieves the machines that were seen with the
+// provided internal IP address around the provided time.
+// The API only looks 15 minutes before and after timestamp.
+func (s *MachineService) FindByIP(ctx context.Context, ip string, timestamp time.Time) (*Response, []Machine, error) {
+	path := fmt.Sprintf("machines/findbyip(ip='%s',timestamp=%s)", ip, timestamp.UTC().Format(odataDatetimeFormat))
+	req, err := s.client.newRequest("GET", path, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachines(ctx, req)
+}
+
+// FindByTag retrieves the machines having the provided tag, or a tag
+// starting with the provided value if useStartsWithFilter is set.
+func (s *MachineService) FindByTag(ctx context.Context, tag string, useStartsWithFilter bool) (*Response, []Machine, error) {
+	queryParams := url.Values{}
+	queryParams.Set("tag", tag)
+	queryParams.Set("useStartsWithFilter", strconv.FormatBool(useStartsWithFilter))
+	req, err := s.client.newRequest("GET", "machines/findbytag", queryParams, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachines(ctx, req)
+}
+
+// ListSoftware retrieves the software installed on a machine.
+func (s *MachineService) ListSoftware(ctx context.Context, id string) (*Response, []Software, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machines/%s/software", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listSoftware(ctx, req)
+}
+
+// ListVulnerabilities retrieves the vulnerabilities a machine is exposed to.
+func (s *MachineService) ListVulnerabilities(ctx context.Context, id string) (*Response, []Vulnerability, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machines/%s/vulnerabilities", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listVulnerabilities(ctx, req)
+}
+
+// ListRecommendations retrieves the security recommendations of a machine.
+func (s *MachineService) ListRecommendations(ctx context.Context, id string) (*Response, []Recommendation, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("machines/%s/recommendations", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listRecommendations(ctx, req)
+}
+
+// listMachines performs req and returns the machines of every page.
+func (c *Client) listMachines(ctx context.Context, req *http.Request) (*Response, []Machine, error) {
+	var machines []Machine
+	newPage := func() pager { return &MachineListResponse{} }
+	resp, err := c.doPages(ctx, req, newPage, func(p pager) error {
+		machines = append(machines, p.(*MachineListResponse).Value...)
+		return nil
+	})
+	return resp, machines, err
+}
+
+// MachineListResponse represents a JSON Object returned by
+// the List Machines endpoint.
+type MachineListResponse struct {
+	ODataPage
+	Value []Machine
+}
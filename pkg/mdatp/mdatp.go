@@ -1,6 +1,7 @@
 package mdatp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -22,11 +23,21 @@ var (
 	defaultTimeout    = 30 * time.Second
 )
 
+// Sentinel errors matched, using errors.Is, by the
+// *ErrorResponse returned for the corresponding status codes.
 var (
 	// ErrBadRequest is a 400 http error.
 	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized is a 401 http error.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is a 403 http error.
+	ErrForbidden = errors.New("forbidden")
 	// ErrNotFound is a 404 http error.
 	ErrNotFound = errors.New("not found")
+	// ErrThrottled is a 429 http error.
+	ErrThrottled = errors.New("throttled")
+	// ErrServerError is a 5xx http error.
+	ErrServerError = errors.New("server error")
 )
 
 // service holds a pointer to the Client for service related
@@ -43,15 +54,37 @@ type Client struct {
 	userAgent string
 	version   string
 
-	logger     *logrus.Logger
-	httpClient *http.Client
+	logger      *logrus.Logger
+	httpClient  *http.Client
+	retryPolicy RetryPolicy
+	rateLimiter *rateLimiter
+
+	// downloadHTTPClient is used to download content from
+	// pre-authenticated URIs, which must not receive the
+	// credentials of httpClient.
+	downloadHTTPClient *http.Client
 
 	// inspired by go-github:
 	// https://github.com/google/go-github/blob/d913de9ce1e8ed5550283b448b37b721b61cc3b3/github/github.go#L159
 	// Reuse a single struct instead of allocating one for each service on the heap.
 	common service
 
-	Alert *AlertService
+	Alert          *AlertService
+	Machine        *MachineService
+	MachineAction  *MachineActionService
+	Indicator      *IndicatorService
+	Hunting        *HuntingService
+	Investigation  *InvestigationService
+	File           *FileService
+	IP             *IPService
+	Domain         *DomainService
+	User           *UserService
+	Software       *SoftwareService
+	Vulnerability  *VulnerabilityService
+	Recommendation *RecommendationService
+	Remediation    *RemediationService
+	Score          *ScoreService
+	LiveResponse   *LiveResponseService
 }
 
 // ClientOption provides a way to confgigure the client.
@@ -106,6 +139,8 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 		version:    defaultVersion,
 		logger:     logrus.New(),
 		httpClient: &http.Client{Timeout: defaultTimeout},
+
+		downloadHTTPClient: &http.Client{},
 	}
 	for _, opt := range opts {
 		if err := opt(c); err != nil {
@@ -114,6 +149,21 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	}
 	c.common.client = c
 	c.Alert = (*AlertService)(&c.common)
+	c.Machine = (*MachineService)(&c.common)
+	c.MachineAction = (*MachineActionService)(&c.common)
+	c.Indicator = (*IndicatorService)(&c.common)
+	c.Hunting = (*HuntingService)(&c.common)
+	c.Investigation = (*InvestigationService)(&c.common)
+	c.File = (*FileService)(&c.common)
+	c.IP = (*IPService)(&c.common)
+	c.Domain = (*DomainService)(&c.common)
+	c.User = (*UserService)(&c.common)
+	c.Software = (*SoftwareService)(&c.common)
+	c.Vulnerability = (*VulnerabilityService)(&c.common)
+	c.Recommendation = (*RecommendationService)(&c.common)
+	c.Remediation = (*RemediationService)(&c.common)
+	c.Score = (*ScoreService)(&c.common)
+	c.LiveResponse = (*LiveResponseService)(&c.common)
 	return c, nil
 }
 
@@ -125,8 +175,29 @@ func (c *Client) Version() string {
 // newRequest generates a http.Request based on the method
 // and endpoint provided. Default headers are also set here.
 func (c *Client) newRequest(method, path string, params url.Values, payload io.Reader) (*http.Request, error) {
-	url := c.getURL(path, params)
-	req, err := http.NewRequest(method, url.String(), payload)
+	return c.newRequestURL(method, c.getURL(path, params).String(), payload)
+}
+
+// newJSONRequest generates a http.Request like newRequest,
+// using the JSON encoding of body as payload.
+func (c *Client) newJSONRequest(method, path string, params url.Values, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.newRequest(method, path, params, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// newRequestURL generates a http.Request based on the method
+// and absolute URL provided, such as an @odata.nextLink.
+// Default headers are also set here.
+func (c *Client) newRequestURL(method, url string, payload io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -137,16 +208,22 @@ func (c *Client) newRequest(method, path string, params url.Values, payload io.R
 
 // getURL returns a URL based on the client version.
 func (c *Client) getURL(path string, params url.Values) *url.URL {
+	path = fmt.Sprintf("/api/%s/%s", c.version, path)
 	return &url.URL{
 		Scheme:   c.BaseURL.Scheme,
 		Host:     c.BaseURL.Host,
-		Path:     fmt.Sprintf("/api/%s/%s", c.version, path),
+		Path:     path,
+		RawPath:  escapeODataPath(path),
 		RawQuery: params.Encode(),
 	}
 }
 
 // do performs a roundtrip using the underlying client
 // and returns an error, if any.
+// Requests wait for the client rate limit, if any, and
+// are retried according to the client RetryPolicy.
+// Requests that are not idempotent, such as POST, are only
+// retried when the server reports it did not process them.
 // It will also try to decode the body into the provided out interface.
 // It returns the response and any error from decoding.
 func (c *Client) do(ctx context.Context, req *http.Request, out interface{}) (*Response, error) {
@@ -154,18 +231,54 @@ func (c *Client) do(ctx context.Context, req *http.Request, out interface{}) (*R
 		return nil, errors.New("context must be non-nil")
 	}
 	req = req.WithContext(ctx)
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	var attempts int
+	var resp *http.Response
+	for {
+		attempts++
+		if attempts > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		if err := c.rateLimiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		var err error
+		resp, err = c.httpClient.Do(req)
+		wait, retry := c.retryPolicy.retryDelay(ctx, attempts, resp, err)
+		if !retry || !canRetry || !replayable(req.Method, resp, err) {
+			if err != nil {
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				default:
+				}
+				return nil, err
+			}
+			break
+		}
+
+		if err != nil {
+			c.logger.Warnf("request failed, retrying in %v (attempt %d): %v", wait, attempts, err)
+		} else {
+			c.logger.Warnf("request failed with status %v, retrying in %v (attempt %d)", resp.Status, wait, attempts)
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
 		}
-		return nil, err
 	}
 	defer resp.Body.Close()
 
 	response, err := validateResponse(resp)
+	response.Attempts = attempts
 	if err == nil && out != nil {
 		if decErr := json.NewDecoder(resp.Body).Decode(&out); decErr != io.EOF {
 			err = decErr
@@ -175,27 +288,41 @@ func (c *Client) do(ctx context.Context, req *http.Request, out interface{}) (*R
 }
 
 // validateResponse validates the response returned from
-// an API call.
+// an API call. An *ErrorResponse is returned if the status
+// code is not 2xx.
 func validateResponse(r *http.Response) (*Response, error) {
 	resp := &Response{HTTPResponse: r}
 	if c := r.StatusCode; 200 <= c && c <= 299 {
 		return resp, nil
 	}
+	errResp := &ErrorResponse{
+		HTTPResponse: r,
+		StatusCode:   r.StatusCode,
+		RequestID:    r.Header.Get("request-id"),
+	}
+	if errResp.RequestID == "" {
+		errResp.RequestID = r.Header.Get("x-ms-request-id")
+	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		return resp, err
 	}
-	if err := json.Unmarshal(data, &resp.APIError); err != nil {
-		return resp, err
+	errResp.Body = data
+	var apiErr APIError
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Error.Code != "" {
+		errResp.APIError = &apiErr
 	}
-	return resp, nil
+	return resp, errResp
 }
 
 // Response encapsulates the http response received from
-// a successful API call.
+// an API call.
 type Response struct {
 	HTTPResponse *http.Response
-	APIError     *APIError
+
+	// Attempts is the number of attempts made,
+	// including retries, to get HTTPResponse.
+	Attempts int
 }
 
 // APIError represents the JSON returned by the API
@@ -208,6 +335,58 @@ type APIError struct {
 	} `json:"error"`
 }
 
+// ErrorResponse is the error returned when the API responds
+// with a non 2xx status code. It matches the sentinel error
+// of its status code, such as ErrNotFound, using errors.Is.
+type ErrorResponse struct {
+	// HTTPResponse is the response received.
+	// Its body has already been read into Body.
+	HTTPResponse *http.Response
+	StatusCode   int
+	RequestID    string
+	// Body is the raw body of the response.
+	Body []byte
+	// APIError is the decoded Body, or nil if
+	// it is not a JSON API error.
+	APIError *APIError
+}
+
+func (e *ErrorResponse) Error() string {
+	msg := http.StatusText(e.StatusCode)
+	if e.APIError != nil {
+		msg = fmt.Sprintf("%s: %s", e.APIError.Error.Code, e.APIError.Error.Message)
+	}
+	s := fmt.Sprintf("%d %s", e.StatusCode, msg)
+	if e.HTTPResponse != nil && e.HTTPResponse.Request != nil {
+		req := e.HTTPResponse.Request
+		s = fmt.Sprintf("%s %s: %s", req.Method, req.URL, s)
+	}
+	if e.RequestID != "" {
+		s = fmt.Sprintf("%s (request id: %s)", s, e.RequestID)
+	}
+	return s
+}
+
+// Is reports whether target is the sentinel error
+// associated with the status code of e.
+func (e *ErrorResponse) Is(target error) bool {
+	switch c := e.StatusCode; {
+	case c == http.StatusBadRequest:
+		return target == ErrBadRequest
+	case c == http.StatusUnauthorized:
+		return target == ErrUnauthorized
+	case c == http.StatusForbidden:
+		return target == ErrForbidden
+	case c == http.StatusNotFound:
+		return target == ErrNotFound
+	case c == http.StatusTooManyRequests:
+		return target == ErrThrottled
+	case 500 <= c && c <= 599:
+		return target == ErrServerError
+	}
+	return false
+}
+
 // Bool is a helper routine that allocates a new bool value
 // to store v and returns a pointer to it.
 func Bool(v bool) *bool { return &v }
@@ -219,3 +398,12 @@ func Int(v int) *int { return &v }
 // String is a helper routine that allocates a new string value
 // to store v and returns a pointer to it.
 func String(v string) *string { return &v }
+
+// StringValue returns the value pointed to by v,
+// or an empty string if v is nil.
+func StringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
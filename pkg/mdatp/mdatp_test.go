@@ -1,6 +1,37 @@
 package mdatp
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// setup creates a Client pointing at a test HTTP server.
+// Tests register handlers on mux, relative to the API version path.
+func setup(t *testing.T, opts ...ClientOption) (client *Client, mux *http.ServeMux, serverURL string, teardown func()) {
+	mux = http.NewServeMux()
+	apiHandler := http.NewServeMux()
+	apiHandler.Handle(fmt.Sprintf("/api/%s/", defaultVersion), http.StripPrefix(fmt.Sprintf("/api/%s", defaultVersion), mux))
+	server := httptest.NewServer(apiHandler)
+
+	client, err := NewClient(opts...)
+	if err != nil {
+		t.Fatalf("error occured creating client: %v", err)
+	}
+	client.BaseURL, _ = url.Parse(server.URL)
+	return client, mux, server.URL, server.Close
+}
 
 func TestClientDefault(t *testing.T) {
 	client, err := NewClient()
@@ -24,3 +55,875 @@ func TestClientDefault(t *testing.T) {
 		t.Errorf("Version is not default value. got: %v want: %v", version, defaultVersion)
 	}
 }
+
+func TestAlertListAllFollowsNextLink(t *testing.T) {
+	client, mux, serverURL, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("$filter"); got != "status eq 'New'" {
+			t.Errorf("filter not forwarded. got: %v", got)
+		}
+		switch r.URL.Query().Get("$skip") {
+		case "":
+			fmt.Fprintf(w, `{"@odata.nextLink":"%s/api/%s/alerts?$filter=status+eq+'New'&$skip=2","value":[{"id":"1"},{"id":"2"}]}`, serverURL, defaultVersion)
+		case "2":
+			fmt.Fprint(w, `{"value":[{"id":"3"}]}`)
+		default:
+			t.Errorf("unexpected page requested: %v", r.URL.RawQuery)
+		}
+	})
+
+	_, alerts, err := client.Alert.ListAll(context.Background(), "status eq 'New'")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(alerts) != 3 {
+		t.Fatalf("alert count mismatch. got: %v want: %v", len(alerts), 3)
+	}
+	for i, want := range []string{"1", "2", "3"} {
+		if got := *alerts[i].ID; got != want {
+			t.Errorf("alert %d id mismatch. got: %v want: %v", i, got, want)
+		}
+	}
+}
+
+func TestAlertListAllRejectsForeignNextLink(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"@odata.nextLink":"https://attacker.example.com/api/%s/alerts?$skip=1","value":[{"id":"1"}]}`, defaultVersion)
+	})
+
+	if _, _, err := client.Alert.ListAll(context.Background(), ""); err == nil {
+		t.Fatalf("expected an error for a next link on another host")
+	}
+}
+
+func TestClientRetriesThrottledAndServerErrors(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Second}
+	client, mux, _, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			fmt.Fprint(w, `{"value":[{"id":"1"}]}`)
+		}
+	})
+
+	resp, alerts, err := client.Alert.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Attempts != 3 {
+		t.Errorf("attempts mismatch. got: %v want: %v", resp.Attempts, 3)
+	}
+	if len(alerts.Value) != 1 {
+		t.Errorf("alert count mismatch. got: %v want: %v", len(alerts.Value), 1)
+	}
+}
+
+func TestClientDoesNotRetryPostOnServerError(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Second}
+	client, mux, _, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/machines/m1/isolate", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"id":"a1"}`)
+	})
+
+	_, _, err := client.MachineAction.Isolate(context.Background(), "m1", "incident", IsolationTypeFull)
+	if !errors.Is(err, ErrServerError) {
+		t.Fatalf("expected ErrServerError. got: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("call count mismatch. got: %v want: %v", calls, 1)
+	}
+}
+
+func TestClientRetriesThrottledPost(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Second}
+	client, mux, _, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/machines/m1/isolate", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if want := `{"Comment":"incident","IsolationType":"Full"}`; string(body) != want {
+			t.Errorf("body mismatch. got: %s want: %s", body, want)
+		}
+		fmt.Fprint(w, `{"id":"a1"}`)
+	})
+
+	resp, _, err := client.MachineAction.Isolate(context.Background(), "m1", "incident", IsolationTypeFull)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Attempts != 2 {
+		t.Errorf("attempts mismatch. got: %v want: %v", resp.Attempts, 2)
+	}
+}
+
+func TestClientRetriesPutOnServerError(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Second}
+	client, mux, _, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/resource", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if want := `{"value":"v"}`; string(body) != want {
+			t.Errorf("body mismatch. got: %s want: %s", body, want)
+		}
+	})
+
+	req, err := client.newJSONRequest("PUT", "resource", nil, map[string]string{"value": "v"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp, err := client.do(context.Background(), req, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Attempts != 2 {
+		t.Errorf("attempts mismatch. got: %v want: %v", resp.Attempts, 2)
+	}
+}
+
+func TestClientRetryRespectsContext(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Hour, MaxBackoff: time.Hour}
+	client, mux, _, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	if _, _, err := client.Alert.List(ctx, ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled. got: %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 5, 13, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second, true},
+		{"invalid", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v. want: %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2020, 5, 13, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	limiter := newRateLimiter(clock, rateLimit{2, time.Minute}, rateLimit{3, time.Hour})
+
+	for i := 0; i < 2; i++ {
+		if wait := limiter.reserve(false); wait != 0 {
+			t.Fatalf("call %d should not wait. got: %v", i, wait)
+		}
+	}
+	if wait := limiter.reserve(false); wait != 30*time.Second {
+		t.Errorf("per minute limit wait mismatch. got: %v want: %v", wait, 30*time.Second)
+	}
+
+	now = now.Add(time.Minute)
+	if wait := limiter.reserve(false); wait != 0 {
+		t.Fatalf("call after refill should not wait. got: %v", wait)
+	}
+	if wait := limiter.reserve(false); wait <= time.Minute {
+		t.Errorf("per hour limit should apply. got: %v", wait)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded. got: %v", err)
+	}
+}
+
+func TestRateLimiterLowPriority(t *testing.T) {
+	now := time.Date(2020, 5, 13, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	limiter := newRateLimiter(clock, rateLimit{10, time.Minute})
+
+	// low priority requests leave 2 tokens to other requests.
+	for i := 0; i < 8; i++ {
+		if wait := limiter.reserve(true); wait != 0 {
+			t.Fatalf("low priority call %d should not wait. got: %v", i, wait)
+		}
+	}
+	if wait := limiter.reserve(true); wait == 0 {
+		t.Errorf("low priority call should wait for the reserve")
+	}
+	if wait := limiter.reserve(false); wait != 0 {
+		t.Errorf("normal call should use the reserve. got: %v", wait)
+	}
+}
+
+func TestClientReturnsErrorResponse(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	body := `{"error":{"code":"ResourceNotFound","message":"Alert not found","target":"43"}}`
+	mux.HandleFunc("/alerts", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("request-id", "42")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, body)
+	})
+
+	_, _, err := client.Alert.List(context.Background(), "")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound. got: %v", err)
+	}
+	if errors.Is(err, ErrBadRequest) {
+		t.Errorf("error should not match ErrBadRequest")
+	}
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) {
+		t.Fatalf("expected *ErrorResponse. got: %T", err)
+	}
+	if errResp.RequestID != "42" {
+		t.Errorf("request id mismatch. got: %v want: %v", errResp.RequestID, "42")
+	}
+	if string(errResp.Body) != body {
+		t.Errorf("body mismatch. got: %s want: %s", errResp.Body, body)
+	}
+	if errResp.APIError == nil || errResp.APIError.Error.Code != "ResourceNotFound" {
+		t.Errorf("api error not decoded. got: %+v", errResp.APIError)
+	}
+}
+
+func TestAlertUpdate(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/alerts/42", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			t.Errorf("method mismatch. got: %v want: %v", r.Method, "PATCH")
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		want := `{"status":"Resolved","comment":"done"}`
+		if string(body) != want {
+			t.Errorf("body mismatch. got: %s want: %s", body, want)
+		}
+		fmt.Fprint(w, `{"id":"42","status":"Resolved"}`)
+	})
+
+	update := &AlertUpdate{Status: String(AlertStatusResolved), Comment: String("done")}
+	_, alert, err := client.Alert.Update(context.Background(), "42", update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *alert.Status != AlertStatusResolved {
+		t.Errorf("status mismatch. got: %v want: %v", *alert.Status, AlertStatusResolved)
+	}
+}
+
+func TestMachineFindByIPPath(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machines/", func(w http.ResponseWriter, r *http.Request) {
+		want := fmt.Sprintf("/api/%s/machines/findbyip(ip='10.1.1.1',timestamp=2020-05-13T12:00:00Z)", defaultVersion)
+		if r.RequestURI != want {
+			t.Errorf("request URI mismatch. got: %v want: %v", r.RequestURI, want)
+		}
+		fmt.Fprint(w, `{"value":[{"id":"1"}]}`)
+	})
+
+	timestamp := time.Date(2020, 5, 13, 12, 0, 0, 0, time.UTC)
+	_, machines, err := client.Machine.FindByIP(context.Background(), "10.1.1.1", timestamp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(machines) != 1 {
+		t.Errorf("machine count mismatch. got: %v want: %v", len(machines), 1)
+	}
+}
+
+func TestMachineActionWaitForCompletion(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	defer func(d time.Duration) { minTickerInterval = d }(minTickerInterval)
+	minTickerInterval = time.Millisecond
+
+	var calls int
+	mux.HandleFunc("/machineactions/42", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		status := MachineActionStatusInProgress
+		if calls == 3 {
+			status = MachineActionStatusSucceeded
+		}
+		fmt.Fprintf(w, `{"id":"42","status":"%s"}`, status)
+	})
+
+	_, machineAction, err := client.MachineAction.WaitForCompletion(context.Background(), "42", time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !machineAction.IsDone() || calls != 3 {
+		t.Errorf("expected completion after 3 calls. got status %v after %d calls", *machineAction.Status, calls)
+	}
+}
+
+func TestMachineActionWaitForCompletionEmptyResponse(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machineactions/42", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `null`)
+	})
+
+	if _, _, err := client.MachineAction.WaitForCompletion(context.Background(), "42", 0); err == nil {
+		t.Errorf("expected an error for an empty response")
+	}
+}
+
+func TestMachineActionPost(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	type call func(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error)
+	tests := []struct {
+		action string
+		call   call
+		want   string
+	}{
+		{
+			"isolate",
+			func(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+				return client.MachineAction.Isolate(ctx, machineID, comment, IsolationTypeSelective)
+			},
+			`{"Comment":"incident","IsolationType":"Selective"}`,
+		},
+		{"unisolate", client.MachineAction.Unisolate, `{"Comment":"incident"}`},
+		{
+			"runAntiVirusScan",
+			func(ctx context.Context, machineID, comment string) (*Response, *MachineAction, error) {
+				return client.MachineAction.RunAntiVirusScan(ctx, machineID, comment, ScanTypeQuick)
+			},
+			`{"Comment":"incident","ScanType":"Quick"}`,
+		},
+		{"collectInvestigationPackage", client.MachineAction.CollectInvestigationPackage, `{"Comment":"incident"}`},
+		{"restrictCodeExecution", client.MachineAction.RestrictCodeExecution, `{"Comment":"incident"}`},
+		{"unrestrictCodeExecution", client.MachineAction.UnrestrictCodeExecution, `{"Comment":"incident"}`},
+		{"offboard", client.MachineAction.Offboard, `{"Comment":"incident"}`},
+	}
+	for _, tt := range tests {
+		tt := tt
+		var calls int
+		mux.HandleFunc("/machines/m1/"+tt.action, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if r.Method != "POST" {
+				t.Errorf("%s method mismatch. got: %v want: %v", tt.action, r.Method, "POST")
+			}
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != tt.want {
+				t.Errorf("%s body mismatch. got: %s want: %s", tt.action, body, tt.want)
+			}
+			fmt.Fprint(w, `{"id":"a1","status":"Pending","machineId":"m1"}`)
+		})
+
+		if _, _, err := tt.call(context.Background(), "m1", ""); err == nil {
+			t.Errorf("%s: expected an error without comment", tt.action)
+		}
+		if calls != 0 {
+			t.Errorf("%s: request sent without comment", tt.action)
+		}
+		_, machineAction, err := tt.call(context.Background(), "m1", "incident")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.action, err)
+		}
+		if *machineAction.ID != "a1" || calls != 1 {
+			t.Errorf("%s: machine action mismatch. got: %+v after %d calls", tt.action, machineAction, calls)
+		}
+	}
+}
+
+func TestMachineActionDownloadPackageResumes(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	client, mux, serverURL, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	content := "investigation package content"
+	mux.HandleFunc("/machineactions/42/GetPackageUri", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"value":"%s/api/%s/package.zip"}`, serverURL, defaultVersion)
+	})
+	mux.HandleFunc("/package.zip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("credentials sent to download URI")
+		}
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			// announce the full content but stop halfway.
+			w.Header().Set("Content-Length", fmt.Sprint(len(content)))
+			fmt.Fprint(w, content[:10])
+			return
+		}
+		if rangeHeader != "bytes=10-" {
+			t.Errorf("range mismatch. got: %v want: %v", rangeHeader, "bytes=10-")
+		}
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes 10-%d/%d", len(content)-1, len(content)))
+		w.WriteHeader(http.StatusPartialContent)
+		fmt.Fprint(w, content[10:])
+	})
+
+	var buf bytes.Buffer
+	sum := sha256.Sum256([]byte(content))
+	opts := &DownloadOptions{ExpectedSHA256: hex.EncodeToString(sum[:])}
+	result, err := client.MachineAction.DownloadPackage(context.Background(), "42", &buf, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != content {
+		t.Errorf("content mismatch. got: %q want: %q", buf.String(), content)
+	}
+	if result.Size != int64(len(content)) {
+		t.Errorf("size mismatch. got: %v want: %v", result.Size, len(content))
+	}
+}
+
+func TestMachineActionGetPackageURIEmptyResponse(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machineactions/42/GetPackageUri", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `null`)
+	})
+
+	if _, _, err := client.MachineAction.GetPackageURI(context.Background(), "42"); err == nil {
+		t.Errorf("expected an error for an empty response")
+	}
+}
+
+func TestMachineActionDownloadPackageNotFound(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	client, mux, serverURL, teardown := setup(t, WithRetryPolicy(policy))
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/machineactions/42/GetPackageUri", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"value":"%s/api/%s/package.zip"}`, serverURL, defaultVersion)
+	})
+	mux.HandleFunc("/package.zip", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	var buf bytes.Buffer
+	if _, err := client.MachineAction.DownloadPackage(context.Background(), "42", &buf, nil); err == nil {
+		t.Fatalf("expected an error for a missing package")
+	}
+	if calls != 1 {
+		t.Errorf("call count mismatch. got: %v want: %v", calls, 1)
+	}
+}
+
+func TestIndicatorImportBatches(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	var sizes []int
+	mux.HandleFunc("/indicators/import", func(w http.ResponseWriter, r *http.Request) {
+		var body indicatorImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		sizes = append(sizes, len(body.Indicators))
+		results := make([]IndicatorImportResult, len(body.Indicators))
+		for i, indicator := range body.Indicators {
+			results[i] = IndicatorImportResult{Indicator: indicator.IndicatorValue, IsFailed: Bool(false)}
+		}
+		json.NewEncoder(w).Encode(&indicatorImportResponse{Value: results})
+	})
+
+	indicators := make([]Indicator, 2*maxIndicatorsPerImport+1)
+	for i := range indicators {
+		indicators[i] = Indicator{IndicatorValue: String(fmt.Sprintf("10.0.%d.%d", i/256, i%256)), IndicatorType: IndicatorTypeIPAddress}
+	}
+	_, results, err := client.Indicator.Import(context.Background(), indicators)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{maxIndicatorsPerImport, maxIndicatorsPerImport, 1}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("request sizes mismatch. got: %v want: %v", sizes, want)
+	}
+	if len(results) != len(indicators) {
+		t.Errorf("result count mismatch. got: %v want: %v", len(results), len(indicators))
+	}
+}
+
+func TestIndicatorDelete(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	var calls int
+	mux.HandleFunc("/indicators/42", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method != "DELETE" {
+			t.Errorf("method mismatch. got: %v want: %v", r.Method, "DELETE")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if _, err := client.Indicator.Delete(context.Background(), "42"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("call count mismatch. got: %v want: %v", calls, 1)
+	}
+}
+
+func TestHuntingRun(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/advancedqueries/run", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if want := `{"Query":"DeviceInfo | take 1"}`; string(body) != want {
+			t.Errorf("body mismatch. got: %s want: %s", body, want)
+		}
+		fmt.Fprint(w, `{"Schema":[{"Name":"DeviceId","Type":"String"},{"Name":"ReportId","Type":"Int64"}],"Results":[{"DeviceId":"abc","ReportId":9007199254740993}]}`)
+	})
+
+	_, result, err := client.Hunting.Run(context.Background(), "DeviceInfo | take 1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Schema) != 2 || result.Schema[1].Type != "Int64" {
+		t.Errorf("schema mismatch. got: %+v", result.Schema)
+	}
+	if got := result.Results[0]["ReportId"]; got != json.Number("9007199254740993") {
+		t.Errorf("long value not preserved. got: %v", got)
+	}
+}
+
+func TestHuntingRunJob(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	end := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+	var queries []string
+	mux.HandleFunc("/advancedqueries/run", func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Query string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		queries = append(queries, body.Query)
+		fmt.Fprint(w, `{"Schema":[{"Name":"DeviceName","Type":"String"}],"Results":[{"DeviceName":"host01"}]}`)
+	})
+
+	job := HuntingJob{Name: "job", Query: "DeviceEvents", Interval: time.Hour}
+	state := NewWatchStateJSON()
+	recordCh := make(chan interface{}, 2)
+	for i := 0; i < 2; i++ {
+		if err := client.Hunting.runJob(context.Background(), job, state, end.Add(time.Duration(i)*time.Hour), recordCh); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := []string{
+		huntingWindowQuery("DeviceEvents", end.Add(-time.Hour), end),
+		huntingWindowQuery("DeviceEvents", end, end.Add(time.Hour)),
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Errorf("queries mismatch. got: %q want: %q", queries, want)
+	}
+	if last, _ := state.GetLastRunTime("job"); !last.Equal(end.Add(time.Hour)) {
+		t.Errorf("last run time mismatch. got: %v want: %v", last, end.Add(time.Hour))
+	}
+	record := (<-recordCh).(HuntingRecord)
+	if record.Job != "job" || record.Row["DeviceName"] != "host01" {
+		t.Errorf("record mismatch. got: %+v", record)
+	}
+}
+
+func TestHuntingRunJobEmptyResponse(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/advancedqueries/run", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `null`)
+	})
+
+	end := time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC)
+	job := HuntingJob{Name: "job", Query: "DeviceEvents", Interval: time.Hour}
+	state := NewWatchStateJSON()
+	if err := client.Hunting.runJob(context.Background(), job, state, end, make(chan interface{}, 1)); err == nil {
+		t.Errorf("expected an error for an empty response")
+	}
+	if last, _ := state.GetLastRunTime("job"); !last.IsZero() {
+		t.Errorf("last run time should not be saved. got: %v", last)
+	}
+}
+
+func TestInvestigationStart(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machines/m1/startInvestigation", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("method mismatch. got: %v want: %v", r.Method, "POST")
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"Comment":"suspicious"}`; string(b) != want {
+			t.Errorf("body mismatch. got: %s want: %s", b, want)
+		}
+		fmt.Fprint(w, `{"id":"42","investigationState":"Queued","machineId":"m1"}`)
+	})
+
+	if _, _, err := client.Investigation.Start(context.Background(), "m1", ""); err == nil {
+		t.Errorf("expected an error without comment")
+	}
+	_, investigation, err := client.Investigation.Start(context.Background(), "m1", "suspicious")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *investigation.ID != "42" || *investigation.InvestigationState != InvestigationStateQueued {
+		t.Errorf("investigation mismatch. got: %+v", investigation)
+	}
+	if investigation.InvestigationState.IsDone() {
+		t.Errorf("queued investigation reported as done")
+	}
+}
+
+func TestFileStats(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/files/abc/stats", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("lookBackHours"); got != "48" {
+			t.Errorf("lookBackHours mismatch. got: %v want: %v", got, "48")
+		}
+		fmt.Fprint(w, `{"sha1":"abc","orgPrevalence":"3","globalPrevalence":179154,"topFileNames":["a.exe"]}`)
+	})
+
+	_, stats, err := client.File.Stats(context.Background(), "abc", 48)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *stats.OrgPrevalence != "3" || *stats.GlobalPrevalence != "179154" {
+		t.Errorf("stats mismatch. got: %+v", stats)
+	}
+}
+
+func TestMachineListVulnerabilities(t *testing.T) {
+	client, mux, serverURL, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machines/m1/vulnerabilities", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skip") == "" {
+			fmt.Fprintf(w, `{"@odata.nextLink":"%s/api/%s/machines/m1/vulnerabilities?$skip=1","value":[{"id":"CVE-2019-0608","cvssV3":4.3}]}`, serverURL, defaultVersion)
+			return
+		}
+		fmt.Fprint(w, `{"value":[{"id":"CVE-2020-0601","publicExploit":true}]}`)
+	})
+
+	_, vulnerabilities, err := client.Machine.ListVulnerabilities(context.Background(), "m1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vulnerabilities) != 2 || *vulnerabilities[1].ID != "CVE-2020-0601" || *vulnerabilities[0].CvssV3 != 4.3 {
+		t.Errorf("vulnerabilities mismatch. got: %+v", vulnerabilities)
+	}
+}
+
+func TestScoreSetDeviceValue(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machines/m1/setDeviceValue", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"DeviceValue":"High"}`; string(b) != want {
+			t.Errorf("body mismatch. got: %s want: %s", b, want)
+		}
+		fmt.Fprint(w, `{"id":"m1","deviceValue":"High"}`)
+	})
+
+	if _, _, err := client.Score.SetDeviceValue(context.Background(), "m1", DeviceValue("Critical")); err == nil {
+		t.Errorf("expected an error for an invalid device value")
+	}
+	_, machine, err := client.Score.SetDeviceValue(context.Background(), "m1", DeviceValueHigh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *machine.DeviceValue != "High" {
+		t.Errorf("device value mismatch. got: %v want: %v", *machine.DeviceValue, "High")
+	}
+}
+
+func TestMachineTagForMultipleMachines(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	var sizes []int
+	mux.HandleFunc("/machines/AddOrRemoveTagForMultipleMachines", func(w http.ResponseWriter, r *http.Request) {
+		var body machineTagRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		if body.Value != "prod" || body.Action != MachineTagActionAdd {
+			t.Errorf("body mismatch. got: %+v", body)
+		}
+		sizes = append(sizes, len(body.MachineIDs))
+		if len(sizes) == 4 {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	ids := make([]string, maxMachinesPerTagRequest+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("m%d", i)
+	}
+	_, applied, err := client.Machine.AddOrRemoveTagForMultipleMachines(context.Background(), ids, "prod", MachineTagActionAdd)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []int{maxMachinesPerTagRequest, 1}; !reflect.DeepEqual(sizes, want) {
+		t.Errorf("request sizes mismatch. got: %v want: %v", sizes, want)
+	}
+	if applied != len(ids) {
+		t.Errorf("applied mismatch. got: %v want: %v", applied, len(ids))
+	}
+
+	// the fourth request fails, after the first chunk of ids is applied.
+	_, applied, err = client.Machine.AddOrRemoveTagForMultipleMachines(context.Background(), ids, "prod", MachineTagActionAdd)
+	if !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest. got: %v", err)
+	}
+	if applied != maxMachinesPerTagRequest {
+		t.Errorf("applied mismatch. got: %v want: %v", applied, maxMachinesPerTagRequest)
+	}
+}
+
+func TestLiveResponseRun(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machines/m1/runliveresponse", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		want := `{"Commands":[{"type":"RunScript","params":[{"key":"ScriptName","value":"dump.ps1"},{"key":"Args","value":"-All"}]},{"type":"GetFile","params":[{"key":"Path","value":"C:\\dump.zip"}]}],"Comment":"incident"}`
+		if string(b) != want {
+			t.Errorf("body mismatch. got: %s want: %s", b, want)
+		}
+		fmt.Fprint(w, `{"id":"a1","status":"Pending","commands":[{"index":0,"commandStatus":"Created","command":{"type":"RunScript"}}]}`)
+	})
+	mux.HandleFunc("/machineactions/a1/GetLiveResponseResultDownloadLink(index=1)", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"value":"https://example.com/result"}`)
+	})
+
+	_, machineAction, err := client.LiveResponse.Run(context.Background(), "m1", "incident", RunScript("dump.ps1", "-All"), GetFile(`C:\dump.zip`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(machineAction.Commands) != 1 || *machineAction.Commands[0].CommandStatus != "Created" {
+		t.Errorf("commands mismatch. got: %+v", machineAction.Commands)
+	}
+	_, uri, err := client.LiveResponse.GetCommandResultURI(context.Background(), "a1", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if uri != "https://example.com/result" {
+		t.Errorf("uri mismatch. got: %v want: %v", uri, "https://example.com/result")
+	}
+}
+
+func TestLiveResponseUploadLibraryFile(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/libraryfiles", func(w http.ResponseWriter, r *http.Request) {
+		f, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			return
+		}
+		defer f.Close()
+		content, _ := ioutil.ReadAll(f)
+		if header.Filename != "script.ps1" || string(content) != "Get-Process" {
+			t.Errorf("file mismatch. got: %s: %s", header.Filename, content)
+		}
+		if got := r.FormValue("OverrideIfExists"); got != "true" {
+			t.Errorf("OverrideIfExists mismatch. got: %v want: %v", got, "true")
+		}
+		fmt.Fprint(w, `{"fileName":"script.ps1"}`)
+	})
+
+	opts := &LibraryFileUploadOptions{OverrideIfExists: true}
+	_, file, err := client.LiveResponse.UploadLibraryFile(context.Background(), "script.ps1", bytes.NewBufferString("Get-Process"), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *file.FileName != "script.ps1" {
+		t.Errorf("file name mismatch. got: %v want: %v", *file.FileName, "script.ps1")
+	}
+}
+
+func TestStopAndQuarantineFile(t *testing.T) {
+	client, mux, _, teardown := setup(t)
+	defer teardown()
+
+	mux.HandleFunc("/machines/m1/StopAndQuarantineFile", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		if want := `{"Comment":"malware","Sha1":"abc"}`; string(b) != want {
+			t.Errorf("body mismatch. got: %s want: %s", b, want)
+		}
+		fmt.Fprint(w, `{"id":"a1","type":"StopAndQuarantineFile","status":"Pending"}`)
+	})
+
+	if _, _, err := client.MachineAction.StopAndQuarantineFile(context.Background(), "m1", "", "malware"); err == nil {
+		t.Errorf("expected an error without sha1")
+	}
+	_, machineAction, err := client.MachineAction.StopAndQuarantineFile(context.Background(), "m1", "abc", "malware")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *machineAction.ID != "a1" {
+		t.Errorf("machine action mismatch. got: %+v", machineAction)
+	}
+}
@@ -1,7 +1,11 @@
 package mdatp
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -10,6 +14,17 @@ var (
 	oDataIntervalQueryStr = "%v gt %v and %v le %v"
 )
 
+// odataPathReplacer restores characters used by OData function
+// calls, such as findbyip(ip='1.2.3.4'), which url.URL would
+// otherwise percent-encode.
+var odataPathReplacer = strings.NewReplacer("%28", "(", "%29", ")", "%27", "'")
+
+// escapeODataPath returns the escaped form of path,
+// suitable for url.URL.RawPath.
+func escapeODataPath(path string) string {
+	return odataPathReplacer.Replace((&url.URL{Path: path}).EscapedPath())
+}
+
 // For now, we do it dirty, but it would not be that hard to have
 // a string builder like type with helper methods for creating
 // arbitrary OData queries.
@@ -21,3 +36,64 @@ func makeIntervalOdataQuery(field string, start, end time.Time) string {
 		end.UTC().Format(odataDatetimeFormat),
 	)
 }
+
+// ODataPage holds the OData annotations returned by the API
+// along with a collection. ODataNextLink is set when the
+// collection has more results than a single page can hold.
+type ODataPage struct {
+	ODataContext  string `json:"@odata.context"`
+	ODataNextLink string `json:"@odata.nextLink,omitempty"`
+}
+
+func (p *ODataPage) nextLink() string {
+	return p.ODataNextLink
+}
+
+// pager is implemented by collection responses embedding ODataPage.
+type pager interface {
+	nextLink() string
+}
+
+// doPages performs the provided request and follows the @odata.nextLink
+// of every page until the collection is exhausted.
+// newPage returns the value to decode a page into, and fn is called
+// once for each page, in order. Any error returned by fn stops the iteration.
+// It returns the response of the last page requested.
+func (c *Client) doPages(ctx context.Context, req *http.Request, newPage func() pager, fn func(pager) error) (*Response, error) {
+	for {
+		page := newPage()
+		resp, err := c.do(ctx, req, page)
+		if err != nil {
+			return resp, err
+		}
+		if err := fn(page); err != nil {
+			return resp, err
+		}
+		next := page.nextLink()
+		if next == "" {
+			return resp, nil
+		}
+		if err := c.checkNextLink(next); err != nil {
+			return resp, err
+		}
+		c.logger.Debugf("following next link: %v", next)
+		req, err = c.newRequestURL(req.Method, next, nil)
+		if err != nil {
+			return resp, err
+		}
+	}
+}
+
+// checkNextLink returns an error if next does not point to
+// the scheme and host of c.BaseURL, so that the credentials
+// of the client are never sent to another server.
+func (c *Client) checkNextLink(next string) error {
+	u, err := url.Parse(next)
+	if err != nil {
+		return fmt.Errorf("invalid next link %q: %v", next, err)
+	}
+	if !strings.EqualFold(u.Scheme, c.BaseURL.Scheme) || !strings.EqualFold(u.Host, c.BaseURL.Host) {
+		return fmt.Errorf("next link %q does not match base URL %v", next, c.BaseURL)
+	}
+	return nil
+}
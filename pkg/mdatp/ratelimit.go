@@ -0,0 +1,164 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultRateLimitPerMinute is the Microsoft quota of calls per minute.
+	DefaultRateLimitPerMinute = 100
+	// DefaultRateLimitPerHour is the Microsoft quota of calls per hour.
+	DefaultRateLimitPerHour = 1500
+
+	// lowPriorityReserve is the fraction of the capacity of each
+	// bucket that low priority requests leave to other requests.
+	lowPriorityReserve = 0.2
+)
+
+// lowPriorityKey is the context key marking low priority requests.
+type lowPriorityKey struct{}
+
+// WithLowPriority returns a copy of ctx marking the requests made using it
+// as low priority. When a rate limit is set, low priority requests wait
+// until the quota is far from exhausted, so that background work such as
+// scheduled hunting jobs cannot starve other requests sharing the Client.
+func WithLowPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lowPriorityKey{}, true)
+}
+
+func isLowPriority(ctx context.Context) bool {
+	lowPriority, _ := ctx.Value(lowPriorityKey{}).(bool)
+	return lowPriority
+}
+
+// WithRateLimit limits the rate at which requests are sent to the API
+// to perMinute calls per minute and perHour calls per hour.
+// A zero value disables the corresponding limit.
+//
+// The limit applies to every request made using the client, including
+// retries and pages, so goroutines sharing a Client share the quota.
+func WithRateLimit(perMinute, perHour int) ClientOption {
+	return func(c *Client) error {
+		if perMinute < 0 || perHour < 0 {
+			return fmt.Errorf("rate limits must not be negative: %d/minute, %d/hour", perMinute, perHour)
+		}
+		var limits []rateLimit
+		if perMinute > 0 {
+			limits = append(limits, rateLimit{perMinute, time.Minute})
+		}
+		if perHour > 0 {
+			limits = append(limits, rateLimit{perHour, time.Hour})
+		}
+		c.rateLimiter = newRateLimiter(time.Now, limits...)
+		return nil
+	}
+}
+
+// rateLimit allows limit calls per period.
+type rateLimit struct {
+	limit  int
+	period time.Duration
+}
+
+// rateLimiter is a set of token buckets that must all
+// hold a token for a call to proceed.
+type rateLimiter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	buckets []*tokenBucket
+}
+
+func newRateLimiter(now func() time.Time, limits ...rateLimit) *rateLimiter {
+	l := &rateLimiter{now: now}
+	start := now()
+	for _, limit := range limits {
+		l.buckets = append(l.buckets, &tokenBucket{
+			capacity: float64(limit.limit),
+			tokens:   float64(limit.limit),
+			rate:     float64(limit.limit) / float64(limit.period),
+			last:     start,
+		})
+	}
+	return l
+}
+
+// Wait blocks until a token is available in every bucket and consumes it,
+// or until ctx is done. A nil rateLimiter never blocks.
+// See WithLowPriority for low priority requests.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	lowPriority := isLowPriority(ctx)
+	for {
+		wait := l.reserve(lowPriority)
+		if wait == 0 {
+			return nil
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve consumes a token from every bucket if they all hold one,
+// otherwise it returns how long to wait before trying again.
+// Low priority requests also require the reserve of every bucket.
+func (l *rateLimiter) reserve(lowPriority bool) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, b := range l.buckets {
+		b.refill(now)
+		needed := 1.0
+		if lowPriority {
+			needed += b.capacity * lowPriorityReserve
+		}
+		if d := b.delay(needed); d > wait {
+			wait = d
+		}
+	}
+	if wait > 0 {
+		return wait
+	}
+	for _, b := range l.buckets {
+		b.tokens--
+	}
+	return 0
+}
+
+// tokenBucket holds up to capacity tokens, refilled
+// continuously at rate tokens per nanosecond.
+type tokenBucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64
+	last     time.Time
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += float64(elapsed) * b.rate
+		if b.tokens > b.capacity {
+			b.tokens = b.capacity
+		}
+		b.last = now
+	}
+}
+
+// delay returns how long until the bucket holds the needed tokens.
+func (b *tokenBucket) delay(needed float64) time.Duration {
+	if b.tokens >= needed {
+		return 0
+	}
+	d := time.Duration((needed - b.tokens) / b.rate)
+	if d <= 0 {
+		d = 1
+	}
+	return d
+}
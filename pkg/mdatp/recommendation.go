@@ -0,0 +1,139 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RecommendationService .
+type RecommendationService service
+
+// List retrieves a single page of security recommendations using conditions.
+// The ODataNextLink attribute of the returned RecommendationListResponse
+// is set when more recommendations are available.
+func (s *RecommendationService) List(ctx context.Context, odataQueryFilter string) (*Response, *RecommendationListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var recommendations *RecommendationListResponse
+	resp, err := s.client.do(ctx, req, &recommendations)
+	return resp, recommendations, err
+}
+
+// ListPages retrieves security recommendations using conditions, following
+// the @odata.nextLink of each page until all recommendations are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *RecommendationService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*RecommendationListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &RecommendationListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*RecommendationListResponse))
+	})
+}
+
+// ListAll retrieves all security recommendations using conditions, across all pages.
+func (s *RecommendationService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Recommendation, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listRecommendations(ctx, req)
+}
+
+func (s *RecommendationService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "recommendations", queryParams, nil)
+}
+
+// Get retrieves a security recommendation by its ID.
+func (s *RecommendationService) Get(ctx context.Context, id string) (*Response, *Recommendation, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("recommendations/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var recommendation *Recommendation
+	resp, err := s.client.do(ctx, req, &recommendation)
+	return resp, recommendation, err
+}
+
+// ListSoftware retrieves the software a security recommendation is about.
+func (s *RecommendationService) ListSoftware(ctx context.Context, id string) (*Response, []Software, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("recommendations/%s/software", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listSoftware(ctx, req)
+}
+
+// ListMachineReferences retrieves the machines exposed
+// to a security recommendation.
+func (s *RecommendationService) ListMachineReferences(ctx context.Context, id string) (*Response, []MachineReference, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("recommendations/%s/machineReferences", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachineReferences(ctx, req)
+}
+
+// ListVulnerabilities retrieves the vulnerabilities
+// addressed by a security recommendation.
+func (s *RecommendationService) ListVulnerabilities(ctx context.Context, id string) (*Response, []Vulnerability, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("recommendations/%s/vulnerabilities", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listVulnerabilities(ctx, req)
+}
+
+// Recommendation represents a Microsoft Defender ATP
+// Security Recommendation type.
+type Recommendation struct {
+	ID                            *string  `json:"id"`
+	ProductName                   *string  `json:"productName"`
+	RecommendationName            *string  `json:"recommendationName"`
+	Weaknesses                    *int     `json:"weaknesses"`
+	Vendor                        *string  `json:"vendor"`
+	RecommendedVersion            *string  `json:"recommendedVersion"`
+	RecommendationCategory        *string  `json:"recommendationCategory"`
+	SubCategory                   *string  `json:"subCategory"`
+	SeverityScore                 *float64 `json:"severityScore"`
+	PublicExploit                 *bool    `json:"publicExploit"`
+	ActiveAlert                   *bool    `json:"activeAlert"`
+	AssociatedThreats             []string `json:"associatedThreats"`
+	RemediationType               *string  `json:"remediationType"`
+	Status                        *string  `json:"status"`
+	ConfigScoreImpact             *float64 `json:"configScoreImpact"`
+	ExposureImpact                *float64 `json:"exposureImpact"`
+	TotalMachineCount             *int     `json:"totalMachineCount"`
+	ExposedMachinesCount          *int     `json:"exposedMachinesCount"`
+	NonProductivityImpactedAssets *int     `json:"nonProductivityImpactedAssets"`
+	RelatedComponent              *string  `json:"relatedComponent"`
+}
+
+// RecommendationListResponse represents a JSON Object returned by
+// the List Recommendations endpoint.
+type RecommendationListResponse struct {
+	ODataPage
+	Value []Recommendation
+}
+
+// listRecommendations performs req and returns the recommendations of every page.
+func (c *Client) listRecommendations(ctx context.Context, req *http.Request) (*Response, []Recommendation, error) {
+	var recommendations []Recommendation
+	newPage := func() pager { return &RecommendationListResponse{} }
+	resp, err := c.doPages(ctx, req, newPage, func(p pager) error {
+		recommendations = append(recommendations, p.(*RecommendationListResponse).Value...)
+		return nil
+	})
+	return resp, recommendations, err
+}
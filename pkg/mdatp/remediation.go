@@ -0,0 +1,117 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// RemediationService .
+type RemediationService service
+
+// List retrieves a single page of remediation activities using conditions.
+// The ODataNextLink attribute of the returned RemediationActivityListResponse
+// is set when more remediation activities are available.
+func (s *RemediationService) List(ctx context.Context, odataQueryFilter string) (*Response, *RemediationActivityListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var activities *RemediationActivityListResponse
+	resp, err := s.client.do(ctx, req, &activities)
+	return resp, activities, err
+}
+
+// ListPages retrieves remediation activities using conditions, following
+// the @odata.nextLink of each page until all remediation activities are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *RemediationService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*RemediationActivityListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &RemediationActivityListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*RemediationActivityListResponse))
+	})
+}
+
+// ListAll retrieves all remediation activities using conditions, across all pages.
+func (s *RemediationService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []RemediationActivity, error) {
+	var activities []RemediationActivity
+	resp, err := s.ListPages(ctx, odataQueryFilter, func(page *RemediationActivityListResponse) error {
+		activities = append(activities, page.Value...)
+		return nil
+	})
+	return resp, activities, err
+}
+
+func (s *RemediationService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "remediationTasks", queryParams, nil)
+}
+
+// Get retrieves a remediation activity by its ID.
+func (s *RemediationService) Get(ctx context.Context, id string) (*Response, *RemediationActivity, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("remediationTasks/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var activity *RemediationActivity
+	resp, err := s.client.do(ctx, req, &activity)
+	return resp, activity, err
+}
+
+// ListMachineReferences retrieves the machines
+// exposed to a remediation activity.
+func (s *RemediationService) ListMachineReferences(ctx context.Context, id string) (*Response, []MachineReference, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("remediationTasks/%s/machineReferences", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachineReferences(ctx, req)
+}
+
+// RemediationActivityListResponse represents a JSON Object returned by
+// the List Remediation Activities endpoint.
+type RemediationActivityListResponse struct {
+	ODataPage
+	Value []RemediationActivity
+}
+
+// RemediationActivity represents a Microsoft Defender ATP
+// Remediation Activity type, created from a security recommendation.
+type RemediationActivity struct {
+	ID                   *string `json:"id"`
+	Title                *string `json:"title"`
+	Description          *string `json:"description"`
+	Category             *string `json:"category"`
+	Type                 *string `json:"type"`
+	Priority             *string `json:"priority"`
+	Status               *string `json:"status"`
+	StatusLastModifiedOn *string `json:"statusLastModifiedOn"`
+	CreatedOn            *string `json:"createdOn"`
+	DueOn                *string `json:"dueOn"`
+	RequesterID          *string `json:"requesterId"`
+	RequesterEmail       *string `json:"requesterEmail"`
+	RequesterNotes       *string `json:"requesterNotes"`
+	CompleterID          *string `json:"completerId"`
+	CompleterEmail       *string `json:"completerEmail"`
+	CompletionMethod     *string `json:"completionMethod"`
+	NameID               *string `json:"nameId"`
+	ProductID            *string `json:"productId"`
+	ProductVendor        *string `json:"productVendor"`
+	VendorID             *string `json:"vendorId"`
+	RecommendedProgram   *string `json:"recommendedProgram"`
+	RecommendedVendor    *string `json:"recommendedVendor"`
+	RecommendedVersion   *string `json:"recommendedVersion"`
+	RelatedComponent     *string `json:"relatedComponent"`
+	Scid                 *string `json:"scid"`
+	TargetDevices        *int64  `json:"targetDevices"`
+	FixedDevices         *int64  `json:"fixedDevices"`
+}
@@ -0,0 +1,162 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	// DefaultRetryPolicy is a retry policy suitable for long running
+	// processes such as Watch, which should survive throttling.
+	DefaultRetryPolicy = RetryPolicy{
+		MaxRetries: 4,
+		MinBackoff: 1 * time.Second,
+		MaxBackoff: 2 * time.Minute,
+	}
+)
+
+// RetryPolicy defines how the client retries requests that failed
+// because of throttling (429), transient server errors (5xx)
+// or network errors. Requests that are not idempotent are only
+// retried on 429, or on 503 with a Retry-After header.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries allowed after the first
+	// attempt. Zero disables retries.
+	MaxRetries int
+	// MinBackoff is the base duration of the exponential backoff.
+	MinBackoff time.Duration
+	// MaxBackoff caps the exponential backoff. A request is not retried
+	// when the server asks, using Retry-After, to wait longer than this.
+	MaxBackoff time.Duration
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+// By default, requests are not retried.
+func WithRetryPolicy(p RetryPolicy) ClientOption {
+	return func(c *Client) error {
+		if p.MaxRetries < 0 {
+			return fmt.Errorf("retry policy MaxRetries is negative: %v", p.MaxRetries)
+		}
+		if p.MaxRetries > 0 && p.MinBackoff <= 0 {
+			return fmt.Errorf("retry policy MinBackoff must be positive: %v", p.MinBackoff)
+		}
+		if p.MaxBackoff < p.MinBackoff {
+			return fmt.Errorf("retry policy MaxBackoff(%v) is below MinBackoff(%v)", p.MaxBackoff, p.MinBackoff)
+		}
+		c.retryPolicy = p
+		return nil
+	}
+}
+
+// retryDelay returns how long to wait before retrying a request,
+// given the outcome of the attempt that was just made, and whether
+// it should be retried at all. attempt starts at 1.
+func (p RetryPolicy) retryDelay(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt > p.MaxRetries {
+		return 0, false
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		// retrying will not fix invalid credentials.
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return 0, false
+		}
+		return p.backoff(attempt), true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if d > p.MaxBackoff {
+				return 0, false
+			}
+			return d, true
+		}
+		return p.backoff(attempt), true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return p.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// replayable reports whether a request using method can be sent again
+// after the outcome of the previous attempt. Idempotent methods can
+// always be replayed. Other requests, such as a machine action POST,
+// could be applied twice, so they are only replayed when the server
+// says it did not process them: 429, or 503 with a Retry-After header.
+func replayable(method string, resp *http.Response, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	if err != nil || resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	}
+	return false
+}
+
+// backoff returns a jittered exponential backoff for the provided
+// attempt, between half and the full value of MinBackoff*2^(attempt-1),
+// capped at MaxBackoff.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MaxBackoff
+	if shift := uint(attempt - 1); shift < 32 {
+		if exp := p.MinBackoff << shift; exp > 0 && exp < d {
+			d = exp
+		}
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// parseRetryAfter parses the value of a Retry-After header,
+// which is either a number of seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// sleep waits for the provided duration or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package mdatp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DeviceValue is the value of a machine for the organization,
+// used to weight its exposure.
+type DeviceValue string
+
+// Values accepted as DeviceValue.
+const (
+	DeviceValueLow    DeviceValue = "Low"
+	DeviceValueNormal DeviceValue = "Normal"
+	DeviceValueHigh   DeviceValue = "High"
+)
+
+// ScoreService .
+type ScoreService service
+
+// GetExposureScore retrieves the exposure score of the organization.
+func (s *ScoreService) GetExposureScore(ctx context.Context) (*Response, *Score, error) {
+	return s.get(ctx, "exposureScore")
+}
+
+// ListExposureScoreByMachineGroups retrieves the exposure
+// score of each machine group of the organization.
+func (s *ScoreService) ListExposureScoreByMachineGroups(ctx context.Context) (*Response, []Score, error) {
+	req, err := s.client.newRequest("GET", "exposureScore/ByMachineGroups", nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var scores []Score
+	newPage := func() pager { return &scoreListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		scores = append(scores, p.(*scoreListResponse).Value...)
+		return nil
+	})
+	return resp, scores, err
+}
+
+// GetConfigurationScore retrieves the device secure
+// score of the organization.
+func (s *ScoreService) GetConfigurationScore(ctx context.Context) (*Response, *Score, error) {
+	return s.get(ctx, "configurationScore")
+}
+
+func (s *ScoreService) get(ctx context.Context, path string) (*Response, *Score, error) {
+	req, err := s.client.newRequest("GET", path, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var score *Score
+	resp, err := s.client.do(ctx, req, &score)
+	return resp, score, err
+}
+
+// SetDeviceValue sets the value of a machine, and returns the updated machine.
+func (s *ScoreService) SetDeviceValue(ctx context.Context, machineID string, value DeviceValue) (*Response, *Machine, error) {
+	switch value {
+	case DeviceValueLow, DeviceValueNormal, DeviceValueHigh:
+	default:
+		return nil, nil, errors.New("invalid device value: " + string(value))
+	}
+	payload := &deviceValueRequest{DeviceValue: value}
+	req, err := s.client.newJSONRequest("POST", fmt.Sprintf("machines/%s/setDeviceValue", machineID), nil, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	var machine *Machine
+	resp, err := s.client.do(ctx, req, &machine)
+	return resp, machine, err
+}
+
+// deviceValueRequest represents a JSON Object sent to
+// the Set Device Value endpoint.
+type deviceValueRequest struct {
+	DeviceValue DeviceValue `json:"DeviceValue"`
+}
+
+// Score represents a Microsoft Defender ATP Exposure Score or
+// Configuration Score type. RbacGroupID and RbacGroupName are
+// only set for the scores of machine groups.
+type Score struct {
+	Time          *string  `json:"time"`
+	Score         *float64 `json:"score"`
+	RbacGroupID   *int     `json:"rbacGroupId"`
+	RbacGroupName *string  `json:"rbacGroupName"`
+}
+
+// scoreListResponse represents a JSON Object
+// returned by endpoints listing scores.
+type scoreListResponse struct {
+	ODataPage
+	Value []Score
+}
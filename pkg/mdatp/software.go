@@ -0,0 +1,147 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// SoftwareService .
+type SoftwareService service
+
+// List retrieves a single page of software using conditions.
+// The ODataNextLink attribute of the returned SoftwareListResponse
+// is set when more software is available.
+func (s *SoftwareService) List(ctx context.Context, odataQueryFilter string) (*Response, *SoftwareListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var software *SoftwareListResponse
+	resp, err := s.client.do(ctx, req, &software)
+	return resp, software, err
+}
+
+// ListPages retrieves software using conditions, following
+// the @odata.nextLink of each page until all software is retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *SoftwareService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*SoftwareListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &SoftwareListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*SoftwareListResponse))
+	})
+}
+
+// ListAll retrieves all software using conditions, across all pages.
+func (s *SoftwareService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Software, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listSoftware(ctx, req)
+}
+
+func (s *SoftwareService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "Software", queryParams, nil)
+}
+
+// Get retrieves a software by its ID.
+func (s *SoftwareService) Get(ctx context.Context, id string) (*Response, *Software, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("Software/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var software *Software
+	resp, err := s.client.do(ctx, req, &software)
+	return resp, software, err
+}
+
+// ListDistributions retrieves the versions of a software
+// installed in the organization.
+func (s *SoftwareService) ListDistributions(ctx context.Context, id string) (*Response, []SoftwareDistribution, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("Software/%s/distributions", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var distributions []SoftwareDistribution
+	newPage := func() pager { return &softwareDistributionListResponse{} }
+	resp, err := s.client.doPages(ctx, req, newPage, func(p pager) error {
+		distributions = append(distributions, p.(*softwareDistributionListResponse).Value...)
+		return nil
+	})
+	return resp, distributions, err
+}
+
+// ListMachineReferences retrieves the machines on which a software is installed.
+func (s *SoftwareService) ListMachineReferences(ctx context.Context, id string) (*Response, []MachineReference, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("Software/%s/machineReferences", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachineReferences(ctx, req)
+}
+
+// ListVulnerabilities retrieves the vulnerabilities of a software.
+func (s *SoftwareService) ListVulnerabilities(ctx context.Context, id string) (*Response, []Vulnerability, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("Software/%s/vulnerabilities", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listVulnerabilities(ctx, req)
+}
+
+// listSoftware performs req and returns the software of every page.
+func (c *Client) listSoftware(ctx context.Context, req *http.Request) (*Response, []Software, error) {
+	var software []Software
+	newPage := func() pager { return &SoftwareListResponse{} }
+	resp, err := c.doPages(ctx, req, newPage, func(p pager) error {
+		software = append(software, p.(*SoftwareListResponse).Value...)
+		return nil
+	})
+	return resp, software, err
+}
+
+// SoftwareListResponse represents a JSON Object returned by
+// the List Software endpoint.
+type SoftwareListResponse struct {
+	ODataPage
+	Value []Software
+}
+
+// Software represents a Microsoft Defender ATP Software type,
+// as inventoried by Threat & Vulnerability Management.
+type Software struct {
+	ID              *string  `json:"id"`
+	Name            *string  `json:"name"`
+	Vendor          *string  `json:"vendor"`
+	Weaknesses      *int     `json:"weaknesses"`
+	PublicExploit   *bool    `json:"publicExploit"`
+	ActiveAlert     *bool    `json:"activeAlert"`
+	ExposedMachines *int     `json:"exposedMachines"`
+	ImpactScore     *float64 `json:"impactScore"`
+}
+
+// SoftwareDistribution is a version of a software
+// installed in the organization.
+type SoftwareDistribution struct {
+	Version         *string `json:"version"`
+	Installations   *int    `json:"installations"`
+	Vulnerabilities *int    `json:"vulnerabilities"`
+}
+
+// softwareDistributionListResponse represents a JSON Object
+// returned by the List Software Version Distribution endpoint.
+type softwareDistributionListResponse struct {
+	ODataPage
+	Value []SoftwareDistribution
+}
@@ -0,0 +1,52 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+)
+
+// UserService .
+type UserService service
+
+// ListAlerts retrieves the alerts related to a user,
+// identified by its account name.
+func (s *UserService) ListAlerts(ctx context.Context, id string) (*Response, []Alert, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("users/%s/alerts", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listAlerts(ctx, req)
+}
+
+// ListMachines retrieves the machines a user logged on to,
+// identified by its account name.
+func (s *UserService) ListMachines(ctx context.Context, id string) (*Response, []Machine, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("users/%s/machines", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachines(ctx, req)
+}
+
+// User represents a Microsoft Defender ATP User entity.
+type User struct {
+	ID                      *string `json:"id"`
+	AccountName             *string `json:"accountName"`
+	AccountDomain           *string `json:"accountDomain"`
+	AccountSid              *string `json:"accountSid"`
+	FirstSeen               *string `json:"firstSeen"`
+	LastSeen                *string `json:"lastSeen"`
+	MostPrevalentMachineID  *string `json:"mostPrevalentMachineId"`
+	LeastPrevalentMachineID *string `json:"leastPrevalentMachineId"`
+	LogonTypes              *string `json:"logonTypes"`
+	LogOnMachinesCount      *int    `json:"logOnMachinesCount"`
+	IsDomainAdmin           *bool   `json:"isDomainAdmin"`
+	IsOnlyNetworkUser       *bool   `json:"isOnlyNetworkUser"`
+}
+
+// userListResponse represents a JSON Object
+// returned by endpoints listing users.
+type userListResponse struct {
+	ODataPage
+	Value []User
+}
@@ -0,0 +1,111 @@
+package mdatp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// VulnerabilityService .
+type VulnerabilityService service
+
+// List retrieves a single page of vulnerabilities using conditions.
+// The ODataNextLink attribute of the returned VulnerabilityListResponse
+// is set when more vulnerabilities are available.
+func (s *VulnerabilityService) List(ctx context.Context, odataQueryFilter string) (*Response, *VulnerabilityListResponse, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	var vulnerabilities *VulnerabilityListResponse
+	resp, err := s.client.do(ctx, req, &vulnerabilities)
+	return resp, vulnerabilities, err
+}
+
+// ListPages retrieves vulnerabilities using conditions, following
+// the @odata.nextLink of each page until all vulnerabilities are retrieved.
+// fn is called for every page, in order. Any error returned
+// by fn stops the iteration and is returned as is.
+func (s *VulnerabilityService) ListPages(ctx context.Context, odataQueryFilter string, fn func(*VulnerabilityListResponse) error) (*Response, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, err
+	}
+	newPage := func() pager { return &VulnerabilityListResponse{} }
+	return s.client.doPages(ctx, req, newPage, func(p pager) error {
+		return fn(p.(*VulnerabilityListResponse))
+	})
+}
+
+// ListAll retrieves all vulnerabilities using conditions, across all pages.
+func (s *VulnerabilityService) ListAll(ctx context.Context, odataQueryFilter string) (*Response, []Vulnerability, error) {
+	req, err := s.newListRequest(odataQueryFilter)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listVulnerabilities(ctx, req)
+}
+
+func (s *VulnerabilityService) newListRequest(odataQueryFilter string) (*http.Request, error) {
+	queryParams := url.Values{}
+	if odataQueryFilter != "" {
+		queryParams.Set("$filter", odataQueryFilter)
+	}
+	return s.client.newRequest("GET", "Vulnerabilities", queryParams, nil)
+}
+
+// Get retrieves a vulnerability by its ID, such as CVE-2019-0608.
+func (s *VulnerabilityService) Get(ctx context.Context, id string) (*Response, *Vulnerability, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("Vulnerabilities/%s", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	var vulnerability *Vulnerability
+	resp, err := s.client.do(ctx, req, &vulnerability)
+	return resp, vulnerability, err
+}
+
+// ListMachineReferences retrieves the machines exposed to a vulnerability.
+func (s *VulnerabilityService) ListMachineReferences(ctx context.Context, id string) (*Response, []MachineReference, error) {
+	req, err := s.client.newRequest("GET", fmt.Sprintf("Vulnerabilities/%s/machineReferences", id), nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.client.listMachineReferences(ctx, req)
+}
+
+// listVulnerabilities performs req and returns the vulnerabilities of every page.
+func (c *Client) listVulnerabilities(ctx context.Context, req *http.Request) (*Response, []Vulnerability, error) {
+	var vulnerabilities []Vulnerability
+	newPage := func() pager { return &VulnerabilityListResponse{} }
+	resp, err := c.doPages(ctx, req, newPage, func(p pager) error {
+		vulnerabilities = append(vulnerabilities, p.(*VulnerabilityListResponse).Value...)
+		return nil
+	})
+	return resp, vulnerabilities, err
+}
+
+// VulnerabilityListResponse represents a JSON Object returned by
+// the List Vulnerabilities endpoint.
+type VulnerabilityListResponse struct {
+	ODataPage
+	Value []Vulnerability
+}
+
+// Vulnerability represents a Microsoft Defender ATP Vulnerability type.
+type Vulnerability struct {
+	ID              *string  `json:"id"`
+	Name            *string  `json:"name"`
+	Description     *string  `json:"description"`
+	Severity        *string  `json:"severity"`
+	CvssV3          *float64 `json:"cvssV3"`
+	ExposedMachines *int     `json:"exposedMachines"`
+	PublishedOn     *string  `json:"publishedOn"`
+	UpdatedOn       *string  `json:"updatedOn"`
+	PublicExploit   *bool    `json:"publicExploit"`
+	ExploitVerified *bool    `json:"exploitVerified"`
+	ExploitInKit    *bool    `json:"exploitInKit"`
+	ExploitTypes    []string `json:"exploitTypes"`
+	ExploitURIs     []string `json:"exploitUris"`
+}
@@ -3,6 +3,7 @@ package mdatp
 import (
 	"encoding/json"
 	"io"
+	"sync"
 	"time"
 )
 
@@ -17,30 +18,68 @@ type WatchState interface {
 	Load(ReadWriteCloserMaker) error
 }
 
+// HuntingWatchState is a WatchState that also tracks the last
+// run time of hunting jobs. It is required by Watch when
+// hunting jobs are provided.
+type HuntingWatchState interface {
+	WatchState
+	SetLastRunTime(job string, t time.Time) error
+	GetLastRunTime(job string) (time.Time, error)
+}
+
 // WatchStateJSON .
 type WatchStateJSON struct {
 	LastFetchTime time.Time `json:"lastFetchTime"`
+	// HuntingJobs holds the end of the window covered by the
+	// last successful run of each hunting job, by job name.
+	HuntingJobs map[string]time.Time `json:"huntingJobs,omitempty"`
+
+	mu sync.Mutex
 }
 
 // NewWatchStateJSON returns a WatchState using the provided
 // source as persistence mecanism.
 func NewWatchStateJSON() *WatchStateJSON {
-	return &WatchStateJSON{time.Time{}}
+	return &WatchStateJSON{HuntingJobs: make(map[string]time.Time)}
 }
 
 // SetLastFetchTime implements the WatchState interface.
 func (s *WatchStateJSON) SetLastFetchTime(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.LastFetchTime = t
 	return nil
 }
 
 // GetLastFetchTime implements the WatchState interface.
 func (s *WatchStateJSON) GetLastFetchTime() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.LastFetchTime, nil
 }
 
+// SetLastRunTime implements the HuntingWatchState interface.
+func (s *WatchStateJSON) SetLastRunTime(job string, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.HuntingJobs == nil {
+		s.HuntingJobs = make(map[string]time.Time)
+	}
+	s.HuntingJobs[job] = t
+	return nil
+}
+
+// GetLastRunTime implements the HuntingWatchState interface.
+func (s *WatchStateJSON) GetLastRunTime(job string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.HuntingJobs[job], nil
+}
+
 // Save implements the WatchState interface.
 func (s *WatchStateJSON) Save(rwcMaker ReadWriteCloserMaker) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	rwc, err := rwcMaker()
 	if err != nil {
 		return err
@@ -51,6 +90,8 @@ func (s *WatchStateJSON) Save(rwcMaker ReadWriteCloserMaker) error {
 
 // Load implements the WatchState interface.
 func (s *WatchStateJSON) Load(rwcMaker ReadWriteCloserMaker) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	rwc, err := rwcMaker()
 	if err != nil {
 		return err